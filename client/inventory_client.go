@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/pcbook/pb"
+	"google.golang.org/grpc"
+)
+
+type InventoryClient struct {
+	service pb.InventoryServiceClient
+}
+
+func NewInventoryClient(cc *grpc.ClientConn) *InventoryClient {
+	service := pb.NewInventoryServiceClient(cc)
+	return &InventoryClient{service}
+}
+
+func (inventoryClient *InventoryClient) SetStock(laptopID string, warehouse string, onHand uint32) (*pb.Stock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.SetStockRequest{LaptopId: laptopID, Warehouse: warehouse, OnHand: onHand}
+	res, err := inventoryClient.service.SetStock(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot set stock: %w", err)
+	}
+	log.Printf("laptop %s has %d units in warehouse %s", laptopID, onHand, warehouse)
+	return res.GetStock(), nil
+}
+
+func (inventoryClient *InventoryClient) GetStock(laptopID string) (*pb.GetStockResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := inventoryClient.service.GetStock(ctx, &pb.GetStockRequest{LaptopId: laptopID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get stock: %w", err)
+	}
+	return res, nil
+}
+
+// ReserveStock holds units for ttl, an empty warehouse lets the server pick one
+func (inventoryClient *InventoryClient) ReserveStock(laptopID string, warehouse string, quantity uint32, ttl time.Duration) (*pb.Reservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.ReserveStockRequest{
+		LaptopId:   laptopID,
+		Warehouse:  warehouse,
+		Quantity:   quantity,
+		TtlSeconds: uint32(ttl / time.Second),
+	}
+	res, err := inventoryClient.service.ReserveStock(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reserve stock: %w", err)
+	}
+	log.Printf("reserved %d units of laptop %s with id: %s", quantity, laptopID, res.GetReservation().GetId())
+	return res.GetReservation(), nil
+}
+
+func (inventoryClient *InventoryClient) CommitReservation(reservationID string) (*pb.Stock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := inventoryClient.service.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: reservationID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot commit reservation: %w", err)
+	}
+	return res.GetStock(), nil
+}
+
+func (inventoryClient *InventoryClient) ReleaseReservation(reservationID string) (*pb.Stock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := inventoryClient.service.ReleaseReservation(ctx, &pb.ReleaseReservationRequest{ReservationId: reservationID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot release reservation: %w", err)
+	}
+	return res.GetStock(), nil
+}
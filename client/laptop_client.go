@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"example.com/pcbook/pb"
+	"example.com/pcbook/validate"
+	"google.golang.org/genproto/googleapis/rpc/code"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -84,7 +86,92 @@ func (laptopClient *LaptopClient) UploadImage(laptopID string, imagePath string)
 	log.Printf("image ipload with id: %s, size: %d", res.GetId(), res.GetSize())
 }
 
+// DownloadImage writes the image, or its smallest thumbnail of at least thumbnailSize pixels, to w and returns its info
+func (laptopClient *LaptopClient) DownloadImage(imageID string, thumbnailSize uint32, w io.Writer) (*pb.ImageInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := laptopClient.service.DownloadImage(ctx, &pb.DownloadImageRequest{ImageId: imageID, ThumbnailSize: thumbnailSize})
+	if err != nil {
+		return nil, fmt.Errorf("cannot download image: %w", err)
+	}
+
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("cannot receive image info: %w", err)
+	}
+	info := res.GetInfo()
+
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot receive chunk data: %w", err)
+		}
+		_, err = w.Write(res.GetChunkData())
+		if err != nil {
+			return nil, fmt.Errorf("cannot write image: %w", err)
+		}
+	}
+	log.Printf("image downloaded with id: %s, size: %d", imageID, info.GetSize())
+	return info, nil
+}
+
+// ListLaptopImages returns the images of a laptop in gallery order, the primary image first
+func (laptopClient *LaptopClient) ListLaptopImages(laptopID string) ([]*pb.LaptopImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := laptopClient.service.ListLaptopImages(ctx, &pb.ListLaptopImagesRequest{LaptopId: laptopID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list images: %w", err)
+	}
+	return res.GetImages(), nil
+}
+
+func (laptopClient *LaptopClient) DeleteImage(imageID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := laptopClient.service.DeleteImage(ctx, &pb.DeleteImageRequest{ImageId: imageID})
+	if err != nil {
+		return fmt.Errorf("cannot delete image: %w", err)
+	}
+	log.Printf("deleted image with id: %s", imageID)
+	return nil
+}
+
+func (laptopClient *LaptopClient) SetPrimaryImage(imageID string) ([]*pb.LaptopImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := laptopClient.service.SetPrimaryImage(ctx, &pb.SetPrimaryImageRequest{ImageId: imageID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot set primary image: %w", err)
+	}
+	return res.GetImages(), nil
+}
+
+// ReorderImages changes the gallery order of a laptop, imageIDs must have all of its images
+func (laptopClient *LaptopClient) ReorderImages(laptopID string, imageIDs []string) ([]*pb.LaptopImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := laptopClient.service.ReorderImages(ctx, &pb.ReorderImagesRequest{LaptopId: laptopID, ImageIds: imageIDs})
+	if err != nil {
+		return nil, fmt.Errorf("cannot reorder images: %w", err)
+	}
+	return res.GetImages(), nil
+}
+
 func (laptopClient *LaptopClient) CreateLaptop(laptop *pb.Laptop) {
+	err := validate.Laptop(laptop)
+	if err != nil {
+		log.Fatal("cannot create laptop: ", err)
+	}
+
 	req := &pb.CreateLaptopRequest{
 		Laptop: laptop,
 	}
@@ -106,15 +193,128 @@ func (laptopClient *LaptopClient) CreateLaptop(laptop *pb.Laptop) {
 	log.Printf("created laptop with id: %s", res.Id)
 }
 
-func (laptopClient *LaptopClient) SearchLaptop(filter *pb.Filter) {
-	log.Print("search filter: ", filter)
+// CreateLaptops sends all valid laptops in a single stream, the response tells which of them were created.
+// The laptops that fail validation are not sent, they get an INVALID_ARGUMENT result at their index.
+func (laptopClient *LaptopClient) CreateLaptops(laptops []*pb.Laptop) (*pb.CreateLaptopsResponse, error) {
+	results := make([]*pb.CreateLaptopsResult, len(laptops))
+	var valid []int
+	for i, laptop := range laptops {
+		err := validate.Laptop(laptop)
+		if err != nil {
+			results[i] = &pb.CreateLaptopsResult{
+				Code:    code.Code_INVALID_ARGUMENT,
+				Message: fmt.Sprintf("laptop %d is not valid: %s", i, status.Convert(err).Message()),
+			}
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	if len(valid) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := laptopClient.service.CreateLaptops(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create laptops: %v", err)
+		}
+
+		for _, i := range valid {
+			err := stream.Send(&pb.CreateLaptopRequest{Laptop: laptops[i]})
+			if err != nil {
+				return nil, fmt.Errorf("cannot send laptop: %v - %v", err, stream.RecvMsg(nil))
+			}
+		}
+
+		res, err := stream.CloseAndRecv()
+		if err != nil {
+			return nil, fmt.Errorf("cannot receive response: %v", err)
+		}
+		if len(res.GetResults()) != len(valid) {
+			return nil, fmt.Errorf("cannot create laptops: got %d results for %d laptops", len(res.GetResults()), len(valid))
+		}
+		for j, i := range valid {
+			results[i] = res.GetResults()[j]
+		}
+	}
+
+	res := &pb.CreateLaptopsResponse{Results: results}
+	for _, result := range results {
+		if result.GetCode() == code.Code_OK {
+			res.CreatedCount++
+		} else {
+			res.FailedCount++
+		}
+	}
+	log.Printf("created %d laptops, %d failed", res.GetCreatedCount(), res.GetFailedCount())
+	return res, nil
+}
+
+func (laptopClient *LaptopClient) GetLaptop(laptopID string) (*pb.Laptop, error) {
+	req := &pb.GetLaptopRequest{
+		Id: laptopID,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := laptopClient.service.GetLaptop(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get laptop: %v", err)
+	}
+	return res.GetLaptop(), nil
+}
+
+func (laptopClient *LaptopClient) UpdateLaptop(laptop *pb.Laptop) (*pb.Laptop, error) {
+	err := validate.Laptop(laptop)
+	if err != nil {
+		return nil, fmt.Errorf("cannot update laptop: %w", err)
+	}
+
+	req := &pb.UpdateLaptopRequest{
+		Laptop: laptop,
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	req := &pb.SearchLaptopRequest{
-		Filter: filter,
+	res, err := laptopClient.service.UpdateLaptop(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot update laptop: %v", err)
 	}
+	log.Printf("updated laptop with id: %s", res.GetLaptop().GetId())
+	return res.GetLaptop(), nil
+}
+
+func (laptopClient *LaptopClient) DeleteLaptop(laptopID string) error {
+	req := &pb.DeleteLaptopRequest{
+		Id: laptopID,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := laptopClient.service.DeleteLaptop(ctx, req)
+	if err != nil {
+		return fmt.Errorf("cannot delete laptop: %v", err)
+	}
+	log.Printf("deleted laptop with id: %s", laptopID)
+	return nil
+}
+
+func (laptopClient *LaptopClient) SearchLaptop(filter *pb.Filter) {
+	log.Print("search filter: ", filter)
+	laptopClient.searchLaptop(&pb.SearchLaptopRequest{Filter: filter})
+}
+
+func (laptopClient *LaptopClient) SearchLaptopByQuery(query string) {
+	log.Print("search query: ", query)
+	laptopClient.searchLaptop(&pb.SearchLaptopRequest{Query: query})
+}
+
+func (laptopClient *LaptopClient) searchLaptop(req *pb.SearchLaptopRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
 	stream, err := laptopClient.service.SearchLaptop(ctx, req)
 
@@ -139,6 +339,39 @@ func (laptopClient *LaptopClient) SearchLaptop(filter *pb.Filter) {
 	}
 }
 
+func (laptopClient *LaptopClient) AggregateLaptops(req *pb.AggregateLaptopsRequest) (*pb.AggregateLaptopsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := laptopClient.service.AggregateLaptops(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot aggregate laptops: %v", err)
+	}
+	return res, nil
+}
+
+// WatchLaptops calls onEvent for every laptop that matches filter, then for every change to them,
+// until ctx is done or onEvent returns an error
+func (laptopClient *LaptopClient) WatchLaptops(ctx context.Context, filter *pb.Filter, onEvent func(res *pb.WatchResponse) error) error {
+	stream, err := laptopClient.service.WatchLaptops(ctx, &pb.WatchRequest{Filter: filter})
+	if err != nil {
+		return fmt.Errorf("cannot watch laptops: %v", err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot receive response: %v", err)
+		}
+		err = onEvent(res)
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func (laptopClient *LaptopClient) RateLaptop(laptopIDs []string, scores []float64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -186,3 +419,67 @@ func (laptopClient *LaptopClient) RateLaptop(laptopIDs []string, scores []float6
 	err = <-waitResponse
 	return err
 }
+
+// ExportCatalog calls found for every record the server exports
+func (laptopClient *LaptopClient) ExportCatalog(ctx context.Context, found func(record *pb.CatalogRecord) error) error {
+	stream, err := laptopClient.service.ExportCatalog(ctx, &pb.ExportCatalogRequest{})
+	if err != nil {
+		return fmt.Errorf("cannot export catalog: %v", err)
+	}
+	for {
+		record, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot receive record: %v", err)
+		}
+		err = found(record)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ImportCatalog sends options, then every record that next returns until it returns io.EOF
+func (laptopClient *LaptopClient) ImportCatalog(
+	ctx context.Context,
+	options *pb.ImportCatalogOptions,
+	next func() (*pb.CatalogRecord, error),
+) (*pb.ImportCatalogResponse, error) {
+	// canceling the stream tells the server to stop if next fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := laptopClient.service.ImportCatalog(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot import catalog: %v", err)
+	}
+
+	req := &pb.ImportCatalogRequest{
+		Data: &pb.ImportCatalogRequest_Options{Options: options},
+	}
+	for {
+		err = stream.Send(req)
+		if err != nil {
+			return nil, fmt.Errorf("cannot send stream request: %v - %v", err, stream.RecvMsg(nil))
+		}
+
+		record, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		req = &pb.ImportCatalogRequest{
+			Data: &pb.ImportCatalogRequest_Record{Record: record},
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("cannot receive response: %v", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/pcbook/pb"
+	"google.golang.org/grpc"
+)
+
+// OrderClient orders laptops for the user it is authenticated as, so every method needs an auth interceptor
+type OrderClient struct {
+	service pb.OrderServiceClient
+}
+
+func NewOrderClient(cc *grpc.ClientConn) *OrderClient {
+	service := pb.NewOrderServiceClient(cc)
+	return &OrderClient{service}
+}
+
+// UpdateCart sets the quantity of a laptop in the cart, 0 removes it
+func (orderClient *OrderClient) UpdateCart(laptopID string, quantity uint32) (*pb.Cart, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := orderClient.service.UpdateCart(ctx, &pb.UpdateCartRequest{LaptopId: laptopID, Quantity: quantity})
+	if err != nil {
+		return nil, fmt.Errorf("cannot update cart: %w", err)
+	}
+	return res.GetCart(), nil
+}
+
+func (orderClient *OrderClient) PlaceOrder() (*pb.Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := orderClient.service.PlaceOrder(ctx, &pb.PlaceOrderRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot place order: %w", err)
+	}
+	log.Printf("placed order with id: %s, total: %.2f usd", res.GetOrder().GetId(), res.GetOrder().GetTotalUsd())
+	return res.GetOrder(), nil
+}
+
+func (orderClient *OrderClient) GetOrder(orderID string) (*pb.Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := orderClient.service.GetOrder(ctx, &pb.GetOrderRequest{Id: orderID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get order: %w", err)
+	}
+	return res.GetOrder(), nil
+}
+
+// ListOrders returns the orders of the user, admins can pass another username or none to see every order
+func (orderClient *OrderClient) ListOrders(username string) ([]*pb.Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := orderClient.service.ListOrders(ctx, &pb.ListOrdersRequest{Username: username})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list orders: %w", err)
+	}
+	return res.GetOrders(), nil
+}
+
+func (orderClient *OrderClient) CancelOrder(orderID string) (*pb.Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := orderClient.service.CancelOrder(ctx, &pb.CancelOrderRequest{Id: orderID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot cancel order: %w", err)
+	}
+	log.Printf("canceled order with id: %s", orderID)
+	return res.GetOrder(), nil
+}
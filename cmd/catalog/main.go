@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"example.com/pcbook/client"
+	"example.com/pcbook/pb"
+	"example.com/pcbook/serializer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	refreshDuration = 30 * time.Second
+)
+
+const usage = `usage: catalog export|import -address ADDRESS -file FILE [flags]
+
+export writes every laptop, rating and image of the server to FILE.
+import loads FILE into the server.
+
+flags:
+`
+
+func authMethods() map[string]bool {
+	const laptopServicePath = "/example.pcbook.LaptopService/"
+	return map[string]bool{
+		laptopServicePath + "ExportCatalog": true,
+		laptopServicePath + "ImportCatalog": true,
+	}
+}
+
+var conflictPolicies = map[string]pb.ImportCatalogOptions_ConflictPolicy{
+	"skip":      pb.ImportCatalogOptions_SKIP,
+	"overwrite": pb.ImportCatalogOptions_OVERWRITE,
+	"fail":      pb.ImportCatalogOptions_FAIL,
+}
+
+func exportCatalog(laptopClient *client.LaptopClient, codec serializer.StreamCodec, filename string, dryRun bool) error {
+	writer := io.Discard
+	var file *os.File
+	if !dryRun {
+		var err error
+		file, err = os.Create(filename)
+		if err != nil {
+			return fmt.Errorf("cannot create catalog file: %w", err)
+		}
+		defer file.Close()
+		writer = file
+	}
+	buffered := bufio.NewWriter(writer)
+
+	counts := make(map[string]int)
+	err := laptopClient.ExportCatalog(context.Background(), func(record *pb.CatalogRecord) error {
+		switch record.GetRecord().(type) {
+		case *pb.CatalogRecord_Laptop:
+			counts["laptops"]++
+		case *pb.CatalogRecord_Rating:
+			counts["ratings"]++
+		case *pb.CatalogRecord_Image:
+			counts["images"]++
+		}
+		return codec.Write(record, buffered)
+	})
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err == nil && file != nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		if file != nil {
+			// don't leave a partial catalog behind, it would look like a good backup
+			file.Close()
+			os.Remove(filename)
+		}
+		return err
+	}
+
+	if dryRun {
+		log.Printf("dry run: would export %d laptops, %d ratings and %d images", counts["laptops"], counts["ratings"], counts["images"])
+	} else {
+		log.Printf("exported %d laptops, %d ratings and %d images to %s", counts["laptops"], counts["ratings"], counts["images"], filename)
+	}
+	return nil
+}
+
+func importCatalog(laptopClient *client.LaptopClient, codec serializer.StreamCodec, filename string, options *pb.ImportCatalogOptions) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("cannot open catalog file: %w", err)
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+
+	count := 0
+	next := func() (*pb.CatalogRecord, error) {
+		record := &pb.CatalogRecord{}
+		_, err := codec.Read(record, reader)
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read record %d: %w", count+1, err)
+		}
+		count++
+		return record, nil
+	}
+
+	res, err := laptopClient.ImportCatalog(context.Background(), options, next)
+	if err != nil {
+		return err
+	}
+
+	prefix := "imported"
+	if res.GetDryRun() {
+		prefix = "dry run: would import"
+	}
+	log.Printf("%s %d records: %d created, %d overwritten, %d skipped",
+		prefix, count, res.GetCreated(), res.GetOverwritten(), res.GetSkipped())
+	return nil
+}
+
+func loadTLSCredentials() (credentials.TransportCredentials, error) {
+	// Load certificate of the CA who signed server's certificate
+	pemServerCA, err := os.ReadFile("cert/ca-cert.pem")
+	if err != nil {
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+
+	if !certPool.AppendCertsFromPEM(pemServerCA) {
+		return nil, fmt.Errorf("failed to add server CA's certificate")
+	}
+
+	// Load client's certificate and private key
+	clientCert, err := tls.LoadX509KeyPair("cert/client-cert.pem", "cert/client-key.pem")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the credentials and return it
+	config := &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      certPool,
+	}
+
+	return credentials.NewTLS(config), nil
+}
+
+func newLaptopClient(serverAddress string, enableTLS bool, username string, password string) (*client.LaptopClient, error) {
+	transportOption := grpc.WithInsecure()
+
+	if enableTLS {
+		tlsCredentials, err := loadTLSCredentials()
+		if err != nil {
+			return nil, fmt.Errorf("cannot load TLS credentials: %w", err)
+		}
+		transportOption = grpc.WithTransportCredentials(tlsCredentials)
+	}
+
+	cc1, err := grpc.Dial(serverAddress, transportOption)
+	if err != nil {
+		return nil, fmt.Errorf("cannot dial server: %w", err)
+	}
+
+	authClient := client.NewAuthClient(cc1, username, password)
+	interceptor, err := client.NewAuthInterceptor(authClient, authMethods(), refreshDuration)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create auth interceptor: %w", err)
+	}
+
+	cc2, err := grpc.Dial(
+		serverAddress,
+		transportOption,
+		grpc.WithUnaryInterceptor(interceptor.Unary()),
+		grpc.WithStreamInterceptor(interceptor.Stream()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot dial server: %w", err)
+	}
+	return client.NewLaptopClient(cc2), nil
+}
+
+func main() {
+	flags := flag.NewFlagSet("catalog", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	serverAddress := flags.String("address", "", "the server address")
+	enableTLS := flags.Bool("tls", false, "enable SSL/TLS")
+	username := flags.String("username", "admin1", "the admin user to log in as")
+	password := flags.String("password", "secret", "the password of the admin user")
+	filename := flags.String("file", "", "the catalog file")
+	formatName := flags.String("format", "", "delimited or jsonl, guessed from the file extension by default")
+	conflict := flags.String("conflict", "skip", "what import does with existing ids: skip, overwrite or fail")
+	dryRun := flags.Bool("dry-run", false, "report what would be done without writing anything")
+
+	if len(os.Args) < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	if command != "export" && command != "import" {
+		log.Fatalf("unknown command %q, use export or import", command)
+	}
+	flags.Parse(os.Args[2:])
+
+	if *serverAddress == "" || *filename == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	codec, err := serializer.LookupStreamCodec(*formatName, *filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	policy, ok := conflictPolicies[*conflict]
+	if !ok {
+		log.Fatalf("unknown conflict policy %q, use skip, overwrite or fail", *conflict)
+	}
+
+	log.Printf("dial server %s, TLS = %t", *serverAddress, *enableTLS)
+	laptopClient, err := newLaptopClient(*serverAddress, *enableTLS, *username, *password)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if command == "export" {
+		err = exportCatalog(laptopClient, codec, *filename, *dryRun)
+	} else {
+		err = importCatalog(laptopClient, codec, *filename, &pb.ImportCatalogOptions{
+			OnConflict: policy,
+			DryRun:     *dryRun,
+		})
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"example.com/pcbook/client"
-	"example.com/pcbook/pb"
 	"example.com/pcbook/sample"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -23,16 +22,7 @@ func testSearchLaptop(laptopClient *client.LaptopClient) {
 
 	}
 
-	filter := &pb.Filter{
-		MaxPriceUsd: 3000,
-		MinCpuCore:  4,
-		MinCpuGhz:   2.5,
-		MinRam: &pb.Memory{
-			Value: 8,
-			Unit:  pb.Memory_GIGABYTE,
-		},
-	}
-	laptopClient.SearchLaptop(filter)
+	laptopClient.SearchLaptopByQuery("price<=3000 cpu.cores>=4 cpu.ghz>=2.5 ram>=8GB")
 }
 func testCreateLaptop(laptopClient *client.LaptopClient) {
 	laptopClient.CreateLaptop(sample.NewLaptop())
@@ -82,9 +72,15 @@ const (
 func authMethods() map[string]bool {
 	const laptopServicePath = "/example.pcbook.LaptopService/"
 	return map[string]bool{
-		laptopServicePath + "CreateLaptop": true,
-		laptopServicePath + "UploadImage":  true,
-		laptopServicePath + "RateLaptop":   true,
+		laptopServicePath + "CreateLaptop":    true,
+		laptopServicePath + "CreateLaptops":   true,
+		laptopServicePath + "UpdateLaptop":    true,
+		laptopServicePath + "DeleteLaptop":    true,
+		laptopServicePath + "UploadImage":     true,
+		laptopServicePath + "DeleteImage":     true,
+		laptopServicePath + "SetPrimaryImage": true,
+		laptopServicePath + "ReorderImages":   true,
+		laptopServicePath + "RateLaptop":      true,
 	}
 }
 
@@ -4,16 +4,20 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	goruntime "runtime"
+	"strings"
 	"time"
 
 	"example.com/pcbook/pb"
 	"example.com/pcbook/service"
+	"example.com/pcbook/thumbnail"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -23,6 +27,13 @@ import (
 const (
 	secretKey     = "secret"
 	tokenDuration = 15 * time.Minute
+
+	journalCompactInterval = 10 * time.Minute
+
+	reservationReclaimInterval = time.Minute
+
+	// thumbnailQueueSize is how many uploaded images can wait for their thumbnails
+	thumbnailQueueSize = 256
 )
 
 const (
@@ -44,15 +55,70 @@ func createUser(userStore service.UserStore, username, password, role string) er
 	if err != nil {
 		return err
 	}
-	return userStore.Save(user)
+	err = userStore.Save(user)
+	if errors.Is(err, service.ErrAlreadyExists) {
+		// users survive restarts with a persistent store
+		return nil
+	}
+	return err
+}
+
+func newStores(storeType string, dbPath string, journalDir string) (service.UserStore, service.LaptopStore, service.RatingStore, error) {
+	switch storeType {
+	case "memory":
+		return service.NewInMemoryUserStore(), service.NewInMemoryLaptopStore(), service.NewInMemoryRatingStore(), nil
+	case "sqlite":
+		db, err := service.OpenSQLiteDB(dbPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		log.Printf("using sqlite store at %s", dbPath)
+		return service.NewSQLiteUserStore(db), service.NewSQLiteLaptopStore(db), service.NewSQLiteRatingStore(db), nil
+	case "journal":
+		laptopStore, err := service.NewJournalLaptopStore(journalDir, journalCompactInterval)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		ratingStore, err := service.NewJournalRatingStore(journalDir, journalCompactInterval)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		log.Printf("using journal store at %s", journalDir)
+		return service.NewInMemoryUserStore(), laptopStore, ratingStore, nil
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown store type: %s", storeType)
+	}
 }
 
 func accessibleRoles() map[string][]string {
 	const laptopServicePath = "/example.pcbook.LaptopService/"
+	const inventoryServicePath = "/example.pcbook.InventoryService/"
+	const orderServicePath = "/example.pcbook.OrderService/"
 	return map[string][]string{
-		laptopServicePath + "CreateLaptop": {"admin"},
-		laptopServicePath + "UploadImage":  {"admin"},
-		laptopServicePath + "RateLaptop":   {"admin", "user"},
+		laptopServicePath + "CreateLaptop":    {"admin"},
+		laptopServicePath + "CreateLaptops":   {"admin"},
+		laptopServicePath + "UpdateLaptop":    {"admin"},
+		laptopServicePath + "DeleteLaptop":    {"admin"},
+		laptopServicePath + "UploadImage":     {"admin"},
+		laptopServicePath + "DeleteImage":     {"admin"},
+		laptopServicePath + "SetPrimaryImage": {"admin"},
+		laptopServicePath + "ReorderImages":   {"admin"},
+		laptopServicePath + "RateLaptop":      {"admin", "user"},
+		laptopServicePath + "ExportCatalog":   {"admin"},
+		laptopServicePath + "ImportCatalog":   {"admin"},
+
+		inventoryServicePath + "SetStock":           {"admin"},
+		inventoryServicePath + "ReserveStock":       {"admin", "user"},
+		inventoryServicePath + "CommitReservation":  {"admin", "user"},
+		inventoryServicePath + "ReleaseReservation": {"admin", "user"},
+
+		orderServicePath + "UpdateCart":       {"admin", "user"},
+		orderServicePath + "GetCart":          {"admin", "user"},
+		orderServicePath + "PlaceOrder":       {"admin", "user"},
+		orderServicePath + "GetOrder":         {"admin", "user"},
+		orderServicePath + "ListOrders":       {"admin", "user"},
+		orderServicePath + "CancelOrder":      {"admin", "user"},
+		orderServicePath + "UpdateOrderState": {"admin"},
 	}
 }
 
@@ -87,6 +153,8 @@ func loadTLSCredentials() (credentials.TransportCredentials, error) {
 func runGRPCServer(
 	authServer pb.AuthServiceServer,
 	laptopServer pb.LaptopServiceServer,
+	inventoryServer pb.InventoryServiceServer,
+	orderServer pb.OrderServiceServer,
 	jwtManager *service.JWTManager,
 	enableTLS bool,
 	listener net.Listener,
@@ -113,6 +181,8 @@ func runGRPCServer(
 	pb.RegisterAuthServiceServer(grpcServer, authServer)
 
 	pb.RegisterLaptopServiceServer(grpcServer, laptopServer)
+	pb.RegisterInventoryServiceServer(grpcServer, inventoryServer)
+	pb.RegisterOrderServiceServer(grpcServer, orderServer)
 	reflection.Register(grpcServer)
 
 	log.Printf("start FRPC server at %s, TLS = %t", listener.Addr().String(), enableTLS)
@@ -120,14 +190,24 @@ func runGRPCServer(
 	return grpcServer.Serve(listener)
 }
 
+// restHeaderMatcher passes the idempotency key header to the handlers, along with the default ones
+func restHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, service.IdempotencyKeyHeader) {
+		return service.IdempotencyKeyHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
 func runRestServer(
 	authServer pb.AuthServiceServer,
-	laptopServer pb.LaptopServiceServer,
+	laptopServer *service.LaptopServer,
+	inventoryServer pb.InventoryServiceServer,
 	jwtManager *service.JWTManager,
 	enableTLS bool,
 	listener net.Listener,
 ) error {
-	mux := runtime.NewServeMux()
+	// without the fallback, a form post cannot reach the handler of another method through X-HTTP-Method-Override
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(restHeaderMatcher), runtime.WithDisablePathLengthFallback())
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -141,11 +221,31 @@ func runRestServer(
 		return err
 	}
 
+	err = pb.RegisterInventoryServiceHandlerServer(ctx, mux, inventoryServer)
+	if err != nil {
+		return err
+	}
+
+	// replaces the generated handler, which doesn't support client streaming
+	err = mux.HandlePath("POST", "/v1/laptop/create_batch", laptopServer.CreateLaptopsHandler(mux))
+	if err != nil {
+		return err
+	}
+
+	// replaces the generated handler, which streams JSON messages instead of the image
+	err = mux.HandlePath("GET", "/v1/laptop/images/{image_id}", laptopServer.DownloadImageHandler(mux))
+	if err != nil {
+		return err
+	}
+
+	interceptor := service.NewAuthInterceptor(jwtManager, accessibleRoles())
+	handler := interceptor.HTTPHandler(mux, pb.File_proto_auth_service_proto, pb.File_proto_laptop_service_proto, pb.File_proto_inventory_service_proto)
+
 	log.Printf("start REST server at %s, TLS = %t", listener.Addr().String(), enableTLS)
 	if enableTLS {
-		return http.ServeTLS(listener, mux, serverCertFile, serverKeyFile)
+		return http.ServeTLS(listener, handler, serverCertFile, serverKeyFile)
 	}
-	return http.Serve(listener, mux)
+	return http.Serve(listener, handler)
 
 }
 
@@ -153,23 +253,59 @@ func main() {
 	port := flag.Int("port", 0, "the server port")
 	enableTLS := flag.Bool("tls", false, "enable SSL/TLS")
 	serverType := flag.String("type", "grpc", "type of server (grpc/rest)")
+	storeType := flag.String("store", "memory", "type of store (memory/sqlite/journal)")
+	dbPath := flag.String("db", "pcbook.db", "the sqlite database file, used when store is sqlite")
+	journalDir := flag.String("journal", "data", "the journal directory, used when store is journal")
+	thumbnailWorkers := flag.Int("thumbnail-workers", goruntime.NumCPU(), "how many images are resized at once, 0 to make no thumbnails")
+	checkImages := flag.Bool("check-images", false, "report image files without metadata and metadata without files, then exit")
+	idempotencyWindow := flag.Duration("idempotency-window", service.DefaultIdempotencyWindow, "how long the responses of requests with an idempotency key are replayed, 0 to ignore the keys")
 
 	flag.Parse()
 	log.Printf("start server on port: %d, TLS = %t", *port, *enableTLS)
 
-	userStore := service.NewInMemoryUserStore()
-	err := seedUsers(userStore)
+	userStore, laptopStore, ratingStore, err := newStores(*storeType, *dbPath, *journalDir)
+	if err != nil {
+		log.Fatal("cannot create stores: ", err)
+	}
+
+	err = seedUsers(userStore)
 	if err != nil {
 		log.Fatal("cannd seed users")
 	}
 	jwtManager := service.NewJWTManager(secretKey, tokenDuration)
 	authServer := service.NewAuthServer(userStore, jwtManager)
 
-	laptopStore := service.NewInMemoryLaptopStore()
-	imageStore := service.NewDiskImageStore("img")
-	ratingStore := service.NewInMemoryRatingStore()
+	imageStore, err := service.NewDiskImageStore("img")
+	if err != nil {
+		log.Fatal("cannot create image store: ", err)
+	}
+	if *checkImages {
+		report, err := imageStore.Check()
+		if err != nil {
+			log.Fatal("cannot check images: ", err)
+		}
+		fmt.Printf("orphan files: %v\nmissing files: %v\ninvalid metadata: %v\n", report.OrphanFiles, report.MissingFiles, report.InvalidMetadata)
+		if !report.Consistent() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	stockStore := service.NewInMemoryStockStore(reservationReclaimInterval)
+	inventoryServer := service.NewInventoryServer(laptopStore, stockStore)
+
+	orderServer := service.NewOrderServer(laptopStore, service.NewInMemoryOrderStore(), service.NewInMemoryCartStore())
 
 	laptopServer := service.NewLaptopServer(laptopStore, imageStore, ratingStore)
+	laptopServer.SetStockStore(stockStore)
+	if *thumbnailWorkers > 0 {
+		laptopServer.SetThumbnailer(service.NewThumbnailer(imageStore, thumbnail.DefaultSizes, *thumbnailWorkers, thumbnailQueueSize))
+	}
+	if *idempotencyWindow > 0 {
+		laptopServer.SetIdempotencyCache(service.NewIdempotencyCache(*idempotencyWindow))
+	} else {
+		laptopServer.SetIdempotencyCache(nil)
+	}
 
 	address := fmt.Sprintf("0.0.0.0:%d", *port)
 
@@ -180,9 +316,9 @@ func main() {
 	}
 
 	if *serverType == "grpc" {
-		err = runGRPCServer(authServer, laptopServer, jwtManager, *enableTLS, listener)
+		err = runGRPCServer(authServer, laptopServer, inventoryServer, orderServer, jwtManager, *enableTLS, listener)
 	} else {
-		err = runRestServer(authServer, laptopServer, jwtManager, *enableTLS, listener)
+		err = runRestServer(authServer, laptopServer, inventoryServer, jwtManager, *enableTLS, listener)
 	}
 
 	if err != nil {
@@ -7,6 +7,7 @@ require (
 	github.com/google/uuid v1.3.0
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.15.2
 	github.com/jinzhu/copier v0.3.5
+	github.com/mattn/go-sqlite3 v1.14.16
 	github.com/test-go/testify v1.1.4
 	golang.org/x/crypto v0.7.0
 	google.golang.org/genproto v0.0.0-20230320184635-7606e756e683
@@ -0,0 +1,91 @@
+// Package imageformat recognizes the formats of the images the server accepts from their content,
+// so that the file extension and MIME type of an upload don't have to be taken from the client.
+package imageformat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+var ErrUnsupported = errors.New("unsupported image")
+
+type Format struct {
+	MIMEType string
+	// Extension has a leading dot, such as ".jpg"
+	Extension string
+	Width     int
+	Height    int
+}
+
+// extensions has the formats that are accepted
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// Detect finds the format of an image from its magic number and reads its dimensions from its header.
+// The rest of the image is not decoded.
+func Detect(data []byte) (*Format, error) {
+	mimeType := http.DetectContentType(data)
+	extension, ok := extensions[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("%w: content type %s is not JPEG, PNG or WebP", ErrUnsupported, mimeType)
+	}
+
+	format := &Format{MIMEType: mimeType, Extension: extension}
+	var err error
+	if mimeType == "image/webp" {
+		format.Width, format.Height, err = webpSize(data)
+	} else {
+		var config image.Config
+		config, _, err = image.DecodeConfig(bytes.NewReader(data))
+		format.Width, format.Height = config.Width, config.Height
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read %s header: %v", ErrUnsupported, mimeType, err)
+	}
+	if format.Width <= 0 || format.Height <= 0 {
+		return nil, fmt.Errorf("%w: %s has no pixels", ErrUnsupported, mimeType)
+	}
+	return format, nil
+}
+
+// webpSize reads the dimensions from the first chunk of a WebP file, which is
+// VP8 for lossy images, VP8L for lossless ones and VP8X for the extended format
+func webpSize(data []byte) (int, int, error) {
+	const chunk = 20
+	if len(data) < chunk+10 {
+		return 0, 0, errors.New("file is too short")
+	}
+
+	switch string(data[12:16]) {
+	case "VP8 ":
+		// frame tag, then the start code of a key frame
+		if !bytes.Equal(data[chunk+3:chunk+6], []byte{0x9d, 0x01, 0x2a}) {
+			return 0, 0, errors.New("VP8 frame is not a key frame")
+		}
+		width := binary.LittleEndian.Uint16(data[chunk+6:]) & 0x3fff
+		height := binary.LittleEndian.Uint16(data[chunk+8:]) & 0x3fff
+		return int(width), int(height), nil
+	case "VP8L":
+		if data[chunk] != 0x2f {
+			return 0, 0, errors.New("VP8L signature is missing")
+		}
+		bits := binary.LittleEndian.Uint32(data[chunk+1:])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1, nil
+	case "VP8X":
+		// flags and reserved bytes, then the canvas size minus one on 24 bits
+		width := uint32(data[chunk+4]) | uint32(data[chunk+5])<<8 | uint32(data[chunk+6])<<16
+		height := uint32(data[chunk+7]) | uint32(data[chunk+8])<<8 | uint32(data[chunk+9])<<16
+		return int(width) + 1, int(height) + 1, nil
+	default:
+		return 0, 0, fmt.Errorf("unknown chunk %q", data[12:16])
+	}
+}
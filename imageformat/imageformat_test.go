@@ -0,0 +1,101 @@
+package imageformat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+)
+
+// webp returns the header of a WebP file whose first chunk has the given type and data
+func webp(chunkType string, data ...byte) []byte {
+	chunk := append([]byte(chunkType), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(data)))
+	chunk = append(chunk, data...)
+
+	header := append([]byte("RIFF"), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+len(chunk)))
+	header = append(header, "WEBP"...)
+	return append(header, chunk...)
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	jpegData := bytes.Buffer{}
+	require.NoError(t, jpeg.Encode(&jpegData, image.NewGray(image.Rect(0, 0, 64, 48)), nil))
+	gifData := bytes.Buffer{}
+	require.NoError(t, gif.Encode(&gifData, image.NewGray(image.Rect(0, 0, 4, 4)), nil))
+	pngData := sample.NewPNG(3, 2)
+
+	testCases := []struct {
+		name   string
+		data   []byte
+		format *Format
+	}{
+		{
+			name:   "jpeg",
+			data:   jpegData.Bytes(),
+			format: &Format{MIMEType: "image/jpeg", Extension: ".jpg", Width: 64, Height: 48},
+		},
+		{
+			name:   "png",
+			data:   pngData,
+			format: &Format{MIMEType: "image/png", Extension: ".png", Width: 3, Height: 2},
+		},
+		{
+			name:   "webp_lossy",
+			data:   webp("VP8 ", 0x30, 0x01, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01),
+			format: &Format{MIMEType: "image/webp", Extension: ".webp", Width: 640, Height: 480},
+		},
+		{
+			// 15 - 1 in the 14 bits of the width, 9 - 1 in the next 14 bits
+			name:   "webp_lossless",
+			data:   webp("VP8L", 0x2f, 0x0e, 0x00, 0x02, 0x00, 0, 0, 0, 0, 0),
+			format: &Format{MIMEType: "image/webp", Extension: ".webp", Width: 15, Height: 9},
+		},
+		{
+			name:   "webp_extended",
+			data:   webp("VP8X", 0x10, 0, 0, 0, 0x7f, 0x07, 0x00, 0x37, 0x04, 0x00),
+			format: &Format{MIMEType: "image/webp", Extension: ".webp", Width: 1920, Height: 1080},
+		},
+		{
+			name: "gif",
+			data: gifData.Bytes(),
+		},
+		{
+			name: "text",
+			data: []byte("#!/bin/sh\necho not an image\n"),
+		},
+		{
+			name: "truncated_png",
+			data: pngData[:12],
+		},
+		{
+			name: "webp_without_key_frame",
+			data: webp("VP8 ", 0x31, 0x01, 0x00, 0, 0, 0, 0x80, 0x02, 0xe0, 0x01),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			format, err := Detect(tc.data)
+			if tc.format == nil {
+				require.True(t, errors.Is(err, ErrUnsupported))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.format, format)
+		})
+	}
+}
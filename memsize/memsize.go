@@ -0,0 +1,288 @@
+// Package memsize does arithmetic on pb.Memory values without overflowing,
+// and converts them from and to strings such as "512GB" or "1.5 TB".
+//
+// Units are binary: a kilobyte is 1024 bytes. Any value of any unit fits in 128 bits,
+// so comparisons and additions are exact. Conversions to a single uint64 report ErrOverflow instead of wrapping.
+//
+// It also holds the factor between the weight units of a laptop, which the stores and the query language share.
+package memsize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strings"
+
+	"example.com/pcbook/pb"
+)
+
+// KgPerLb converts the weights in pounds to kilograms
+const KgPerLb = 0.45359237
+
+var (
+	ErrOverflow    = errors.New("memory size overflows")
+	ErrInexact     = errors.New("memory size is not a whole number of the unit")
+	ErrUnknownUnit = errors.New("memory unit is unknown")
+)
+
+// shifts gives the number of bits of each unit as a power of 2
+var shifts = map[pb.Memory_Unit]uint{
+	pb.Memory_BIT:      0,
+	pb.Memory_BYTE:     3,
+	pb.Memory_KILOBYTE: 13,
+	pb.Memory_MEGABYTE: 23,
+	pb.Memory_GIGABYTE: 33,
+	pb.Memory_TERABYTE: 43,
+}
+
+// unitsDescending is used to find the largest unit a size can be written in
+var unitsDescending = []pb.Memory_Unit{
+	pb.Memory_TERABYTE,
+	pb.Memory_GIGABYTE,
+	pb.Memory_MEGABYTE,
+	pb.Memory_KILOBYTE,
+	pb.Memory_BYTE,
+	pb.Memory_BIT,
+}
+
+// size is a number of bits in 128 bits
+type size struct {
+	hi, lo uint64
+}
+
+// sizeOf returns the size of memory. A nil memory or an unknown unit counts as zero.
+func sizeOf(memory *pb.Memory) size {
+	shift, ok := shifts[memory.GetUnit()]
+	if !ok {
+		return size{}
+	}
+	value := memory.GetValue()
+	if shift == 0 {
+		return size{lo: value}
+	}
+	return size{hi: value >> (64 - shift), lo: value << shift}
+}
+
+func (s size) cmp(other size) int {
+	switch {
+	case s.hi < other.hi:
+		return -1
+	case s.hi > other.hi:
+		return 1
+	case s.lo < other.lo:
+		return -1
+	case s.lo > other.lo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (s size) add(other size) (size, error) {
+	lo, carry := bits.Add64(s.lo, other.lo, 0)
+	hi, carry := bits.Add64(s.hi, other.hi, carry)
+	if carry != 0 {
+		return size{}, ErrOverflow
+	}
+	return size{hi: hi, lo: lo}, nil
+}
+
+// in returns the size as a value of unit
+func (s size) in(unit pb.Memory_Unit) (uint64, error) {
+	shift := shifts[unit]
+	if shift == 0 {
+		if s.hi != 0 {
+			return 0, ErrOverflow
+		}
+		return s.lo, nil
+	}
+	if s.lo&(1<<shift-1) != 0 {
+		return 0, ErrInexact
+	}
+	if s.hi>>shift != 0 {
+		return 0, ErrOverflow
+	}
+	return s.hi<<(64-shift) | s.lo>>shift, nil
+}
+
+// memory returns the size in the largest unit that holds it exactly, zero is 0 BIT
+func (s size) memory() (*pb.Memory, error) {
+	if s == (size{}) {
+		return &pb.Memory{Unit: pb.Memory_BIT}, nil
+	}
+	for _, unit := range unitsDescending {
+		value, err := s.in(unit)
+		if err == nil {
+			return &pb.Memory{Value: value, Unit: unit}, nil
+		}
+		if err == ErrOverflow {
+			return nil, err
+		}
+	}
+	return nil, ErrOverflow
+}
+
+func checkUnit(memory *pb.Memory) error {
+	if memory == nil {
+		return nil
+	}
+	if _, ok := shifts[memory.GetUnit()]; !ok {
+		return ErrUnknownUnit
+	}
+	return nil
+}
+
+// ToBits returns the number of bits of memory, 0 for nil
+func ToBits(memory *pb.Memory) (uint64, error) {
+	return Convert(memory, pb.Memory_BIT)
+}
+
+// Convert returns the value of memory in unit. It fails if the value is not a whole number of the unit,
+// or if it doesn't fit in a uint64.
+func Convert(memory *pb.Memory, unit pb.Memory_Unit) (uint64, error) {
+	if err := checkUnit(memory); err != nil {
+		return 0, err
+	}
+	if _, ok := shifts[unit]; !ok {
+		return 0, ErrUnknownUnit
+	}
+	return sizeOf(memory).in(unit)
+}
+
+// InUnit returns memory as a possibly fractional number of unit, e.g. for statistics and sorting.
+// A nil memory or an unknown unit counts as zero.
+func InUnit(memory *pb.Memory, unit pb.Memory_Unit) float64 {
+	s := sizeOf(memory)
+	x := float64(s.hi)*math.Exp2(64) + float64(s.lo)
+	return x / math.Exp2(float64(shifts[unit]))
+}
+
+// Compare returns -1, 0 or 1 when a is smaller than, equal to or larger than b.
+// A nil memory or an unknown unit counts as zero.
+func Compare(a *pb.Memory, b *pb.Memory) int {
+	return sizeOf(a).cmp(sizeOf(b))
+}
+
+// Add returns a + b in canonical form. A nil memory counts as zero.
+func Add(a *pb.Memory, b *pb.Memory) (*pb.Memory, error) {
+	if err := checkUnit(a); err != nil {
+		return nil, err
+	}
+	if err := checkUnit(b); err != nil {
+		return nil, err
+	}
+	sum, err := sizeOf(a).add(sizeOf(b))
+	if err != nil {
+		return nil, err
+	}
+	return sum.memory()
+}
+
+// Canonical returns memory in the largest unit that holds it exactly, e.g. 2048 MB becomes 2 GB.
+// Zero is 0 BIT.
+func Canonical(memory *pb.Memory) (*pb.Memory, error) {
+	if err := checkUnit(memory); err != nil {
+		return nil, err
+	}
+	return sizeOf(memory).memory()
+}
+
+var unitNames = map[pb.Memory_Unit]string{
+	pb.Memory_BIT:      "bit",
+	pb.Memory_BYTE:     "B",
+	pb.Memory_KILOBYTE: "KB",
+	pb.Memory_MEGABYTE: "MB",
+	pb.Memory_GIGABYTE: "GB",
+	pb.Memory_TERABYTE: "TB",
+}
+
+var parseUnits = map[string]pb.Memory_Unit{
+	"bit":  pb.Memory_BIT,
+	"bits": pb.Memory_BIT,
+	"b":    pb.Memory_BYTE,
+	"kb":   pb.Memory_KILOBYTE,
+	"kib":  pb.Memory_KILOBYTE,
+	"mb":   pb.Memory_MEGABYTE,
+	"mib":  pb.Memory_MEGABYTE,
+	"gb":   pb.Memory_GIGABYTE,
+	"gib":  pb.Memory_GIGABYTE,
+	"tb":   pb.Memory_TERABYTE,
+	"tib":  pb.Memory_TERABYTE,
+}
+
+// Format returns memory like "16 GB", or an empty string for nil
+func Format(memory *pb.Memory) string {
+	if memory == nil {
+		return ""
+	}
+	name, ok := unitNames[memory.GetUnit()]
+	if !ok {
+		name = memory.GetUnit().String()
+	}
+	return fmt.Sprintf("%d %s", memory.GetValue(), name)
+}
+
+// ParseError tells where a memory string is invalid
+type ParseError struct {
+	Text string
+	// Offset is the byte offset of the problem in Text
+	Offset int
+	Msg    string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("invalid memory size %q: %s", err.Text, err.Msg)
+}
+
+// Parse reads strings like "512GB", "1.5 TB" or "16 gb". A fractional value is converted to the
+// largest unit that holds it exactly, so "1.5 TB" is 1536 GB. Integer values keep their unit.
+func Parse(text string) (*pb.Memory, error) {
+	i := 0
+	for i < len(text) && (text[i] >= '0' && text[i] <= '9' || text[i] == '.') {
+		i++
+	}
+	number := text[:i]
+	unitName := strings.TrimSpace(text[i:])
+	for i < len(text) && text[i] == ' ' {
+		i++
+	}
+
+	if number == "" || strings.Count(number, ".") > 1 || number[0] == '.' || number[len(number)-1] == '.' {
+		return nil, &ParseError{Text: text, Msg: "expected a number followed by a unit"}
+	}
+	unit, ok := parseUnits[strings.ToLower(unitName)]
+	if !ok {
+		return nil, &ParseError{Text: text, Offset: i, Msg: fmt.Sprintf("unknown unit %q, use one of B, KB, MB, GB or TB", unitName)}
+	}
+
+	value, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return nil, &ParseError{Text: text, Msg: "expected a number followed by a unit"}
+	}
+	if value.IsInt() {
+		if !value.Num().IsUint64() {
+			return nil, &ParseError{Text: text, Msg: ErrOverflow.Error()}
+		}
+		return &pb.Memory{Value: value.Num().Uint64(), Unit: unit}, nil
+	}
+
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), shifts[unit])))
+	if !value.IsInt() {
+		return nil, &ParseError{Text: text, Msg: "not a whole number of bits"}
+	}
+	n := value.Num()
+	if n.BitLen() > 128 {
+		return nil, &ParseError{Text: text, Msg: ErrOverflow.Error()}
+	}
+	s := size{
+		hi: new(big.Int).Rsh(n, 64).Uint64(),
+		lo: new(big.Int).And(n, new(big.Int).SetUint64(math.MaxUint64)).Uint64(),
+	}
+	memory, err := s.memory()
+	if err != nil {
+		return nil, &ParseError{Text: text, Msg: err.Error()}
+	}
+	return memory, nil
+}
@@ -0,0 +1,114 @@
+package memsize
+
+import (
+	"math"
+	"testing"
+
+	"example.com/pcbook/pb"
+	"github.com/test-go/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	huge := &pb.Memory{Value: 1 << 21, Unit: pb.Memory_TERABYTE}
+	require.Equal(t, 1, Compare(huge, &pb.Memory{Value: 1, Unit: pb.Memory_GIGABYTE}))
+	require.Equal(t, -1, Compare(&pb.Memory{Value: math.MaxUint64, Unit: pb.Memory_BIT}, huge))
+	require.Equal(t, 0, Compare(&pb.Memory{Value: 2048, Unit: pb.Memory_MEGABYTE}, &pb.Memory{Value: 2, Unit: pb.Memory_GIGABYTE}))
+	require.Equal(t, 0, Compare(nil, &pb.Memory{Value: 16}))
+	require.Equal(t, -1, Compare(nil, &pb.Memory{Value: 1, Unit: pb.Memory_BIT}))
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	bits, err := ToBits(&pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE})
+	require.NoError(t, err)
+	require.Equal(t, uint64(16<<33), bits)
+
+	_, err = ToBits(&pb.Memory{Value: 1 << 21, Unit: pb.Memory_TERABYTE})
+	require.Equal(t, ErrOverflow, err)
+
+	_, err = ToBits(&pb.Memory{Value: 1})
+	require.Equal(t, ErrUnknownUnit, err)
+
+	value, err := Convert(&pb.Memory{Value: 3, Unit: pb.Memory_TERABYTE}, pb.Memory_GIGABYTE)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3072), value)
+
+	_, err = Convert(&pb.Memory{Value: 1536, Unit: pb.Memory_GIGABYTE}, pb.Memory_TERABYTE)
+	require.Equal(t, ErrInexact, err)
+
+	require.Equal(t, 1.5, InUnit(&pb.Memory{Value: 1536, Unit: pb.Memory_GIGABYTE}, pb.Memory_TERABYTE))
+}
+
+func TestAddAndCanonical(t *testing.T) {
+	t.Parallel()
+
+	sum, err := Add(&pb.Memory{Value: 512, Unit: pb.Memory_GIGABYTE}, &pb.Memory{Value: 512, Unit: pb.Memory_GIGABYTE})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&pb.Memory{Value: 1, Unit: pb.Memory_TERABYTE}, sum), "%v", sum)
+
+	sum, err = Add(nil, &pb.Memory{Value: math.MaxUint64, Unit: pb.Memory_TERABYTE})
+	require.NoError(t, err)
+	_, err = Add(sum, &pb.Memory{Value: 1, Unit: pb.Memory_TERABYTE})
+	require.Equal(t, ErrOverflow, err)
+
+	_, err = Add(nil, &pb.Memory{Value: 1})
+	require.Equal(t, ErrUnknownUnit, err)
+
+	canonical, err := Canonical(&pb.Memory{Value: 24, Unit: pb.Memory_BIT})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&pb.Memory{Value: 3, Unit: pb.Memory_BYTE}, canonical), "%v", canonical)
+
+	canonical, err = Canonical(&pb.Memory{Unit: pb.Memory_GIGABYTE})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&pb.Memory{Unit: pb.Memory_BIT}, canonical), "%v", canonical)
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		text   string
+		memory *pb.Memory
+		offset int
+	}{
+		{text: "512GB", memory: &pb.Memory{Value: 512, Unit: pb.Memory_GIGABYTE}},
+		{text: "16 gb", memory: &pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE}},
+		{text: "1.5 TB", memory: &pb.Memory{Value: 1536, Unit: pb.Memory_GIGABYTE}},
+		{text: "0.5B", memory: &pb.Memory{Value: 4, Unit: pb.Memory_BIT}},
+		{text: "2048 MB", memory: &pb.Memory{Value: 2048, Unit: pb.Memory_MEGABYTE}},
+		{text: "16XB", offset: 2},
+		{text: "16 XB", offset: 3},
+		{text: "GB"},
+		{text: "1.2.3GB"},
+		{text: "0.1 bit"},
+		{text: "18446744073709551616 B"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.text, func(t *testing.T) {
+			t.Parallel()
+
+			memory, err := Parse(tc.text)
+			if tc.memory != nil {
+				require.NoError(t, err)
+				require.True(t, proto.Equal(tc.memory, memory), "%v", memory)
+				require.Equal(t, 0, Compare(memory, mustParse(t, Format(memory))))
+				return
+			}
+			parseErr, ok := err.(*ParseError)
+			require.True(t, ok, "unexpected error: %v", err)
+			require.Equal(t, tc.offset, parseErr.Offset)
+		})
+	}
+}
+
+func mustParse(t *testing.T, text string) *pb.Memory {
+	memory, err := Parse(text)
+	require.NoError(t, err)
+	return memory
+}
@@ -0,0 +1,306 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v3.21.12
+// source: proto/catalog_message.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ImageRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	LaptopId  string `protobuf:"bytes,2,opt,name=laptop_id,json=laptopId,proto3" json:"laptop_id,omitempty"`
+	ImageType string `protobuf:"bytes,3,opt,name=image_type,json=imageType,proto3" json:"image_type,omitempty"`
+	Data      []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *ImageRecord) Reset() {
+	*x = ImageRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_catalog_message_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImageRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageRecord) ProtoMessage() {}
+
+func (x *ImageRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_message_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageRecord.ProtoReflect.Descriptor instead.
+func (*ImageRecord) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_message_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ImageRecord) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ImageRecord) GetLaptopId() string {
+	if x != nil {
+		return x.LaptopId
+	}
+	return ""
+}
+
+func (x *ImageRecord) GetImageType() string {
+	if x != nil {
+		return x.ImageType
+	}
+	return ""
+}
+
+func (x *ImageRecord) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type CatalogRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Record:
+	//
+	//	*CatalogRecord_Laptop
+	//	*CatalogRecord_Rating
+	//	*CatalogRecord_Image
+	Record isCatalogRecord_Record `protobuf_oneof:"record"`
+}
+
+func (x *CatalogRecord) Reset() {
+	*x = CatalogRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_catalog_message_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CatalogRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CatalogRecord) ProtoMessage() {}
+
+func (x *CatalogRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_message_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CatalogRecord.ProtoReflect.Descriptor instead.
+func (*CatalogRecord) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_message_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *CatalogRecord) GetRecord() isCatalogRecord_Record {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
+func (x *CatalogRecord) GetLaptop() *Laptop {
+	if x, ok := x.GetRecord().(*CatalogRecord_Laptop); ok {
+		return x.Laptop
+	}
+	return nil
+}
+
+func (x *CatalogRecord) GetRating() *RatingRecord {
+	if x, ok := x.GetRecord().(*CatalogRecord_Rating); ok {
+		return x.Rating
+	}
+	return nil
+}
+
+func (x *CatalogRecord) GetImage() *ImageRecord {
+	if x, ok := x.GetRecord().(*CatalogRecord_Image); ok {
+		return x.Image
+	}
+	return nil
+}
+
+type isCatalogRecord_Record interface {
+	isCatalogRecord_Record()
+}
+
+type CatalogRecord_Laptop struct {
+	Laptop *Laptop `protobuf:"bytes,1,opt,name=laptop,proto3,oneof"`
+}
+
+type CatalogRecord_Rating struct {
+	Rating *RatingRecord `protobuf:"bytes,2,opt,name=rating,proto3,oneof"`
+}
+
+type CatalogRecord_Image struct {
+	Image *ImageRecord `protobuf:"bytes,3,opt,name=image,proto3,oneof"`
+}
+
+func (*CatalogRecord_Laptop) isCatalogRecord_Record() {}
+
+func (*CatalogRecord_Rating) isCatalogRecord_Record() {}
+
+func (*CatalogRecord_Image) isCatalogRecord_Record() {}
+
+var File_proto_catalog_message_proto protoreflect.FileDescriptor
+
+var file_proto_catalog_message_proto_rawDesc = []byte{
+	0x0a, 0x1b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x5f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x65,
+	0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x1a, 0x1a, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x5f, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1b, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x6a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x6d, 0x0a, 0x0b, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x70, 0x74, 0x6f, 0x70,
+	0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0xb8, 0x01, 0x0a, 0x0d, 0x43, 0x61, 0x74, 0x61, 0x6c, 0x6f,
+	0x67, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x30, 0x0a, 0x06, 0x6c, 0x61, 0x70, 0x74, 0x6f,
+	0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x48,
+	0x00, 0x52, 0x06, 0x6c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x12, 0x36, 0x0a, 0x06, 0x72, 0x61, 0x74,
+	0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x65, 0x78, 0x61, 0x6d,
+	0x70, 0x6c, 0x65, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x52, 0x61, 0x74, 0x69, 0x6e,
+	0x67, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x48, 0x00, 0x52, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e,
+	0x67, 0x12, 0x33, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f,
+	0x6b, 0x2e, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x48, 0x00, 0x52,
+	0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x42, 0x05, 0x5a, 0x03, 0x70, 0x62, 0x2f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_catalog_message_proto_rawDescOnce sync.Once
+	file_proto_catalog_message_proto_rawDescData = file_proto_catalog_message_proto_rawDesc
+)
+
+func file_proto_catalog_message_proto_rawDescGZIP() []byte {
+	file_proto_catalog_message_proto_rawDescOnce.Do(func() {
+		file_proto_catalog_message_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_catalog_message_proto_rawDescData)
+	})
+	return file_proto_catalog_message_proto_rawDescData
+}
+
+var file_proto_catalog_message_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_catalog_message_proto_goTypes = []interface{}{
+	(*ImageRecord)(nil),   // 0: example.pcbook.ImageRecord
+	(*CatalogRecord)(nil), // 1: example.pcbook.CatalogRecord
+	(*Laptop)(nil),        // 2: example.pcbook.Laptop
+	(*RatingRecord)(nil),  // 3: example.pcbook.RatingRecord
+}
+var file_proto_catalog_message_proto_depIdxs = []int32{
+	2, // 0: example.pcbook.CatalogRecord.laptop:type_name -> example.pcbook.Laptop
+	3, // 1: example.pcbook.CatalogRecord.rating:type_name -> example.pcbook.RatingRecord
+	0, // 2: example.pcbook.CatalogRecord.image:type_name -> example.pcbook.ImageRecord
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_proto_catalog_message_proto_init() }
+func file_proto_catalog_message_proto_init() {
+	if File_proto_catalog_message_proto != nil {
+		return
+	}
+	file_proto_laptop_message_proto_init()
+	file_proto_journal_message_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_proto_catalog_message_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImageRecord); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_catalog_message_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CatalogRecord); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_proto_catalog_message_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*CatalogRecord_Laptop)(nil),
+		(*CatalogRecord_Rating)(nil),
+		(*CatalogRecord_Image)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_catalog_message_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_catalog_message_proto_goTypes,
+		DependencyIndexes: file_proto_catalog_message_proto_depIdxs,
+		MessageInfos:      file_proto_catalog_message_proto_msgTypes,
+	}.Build()
+	File_proto_catalog_message_proto = out.File
+	file_proto_catalog_message_proto_rawDesc = nil
+	file_proto_catalog_message_proto_goTypes = nil
+	file_proto_catalog_message_proto_depIdxs = nil
+}
@@ -29,6 +29,28 @@ type Filter struct {
 	MinCpuCore  uint32  `protobuf:"varint,2,opt,name=min_cpu_core,json=minCpuCore,proto3" json:"min_cpu_core,omitempty"`
 	MinCpuGhz   float64 `protobuf:"fixed64,3,opt,name=min_cpu_ghz,json=minCpuGhz,proto3" json:"min_cpu_ghz,omitempty"`
 	MinRam      *Memory `protobuf:"bytes,4,opt,name=min_ram,json=minRam,proto3" json:"min_ram,omitempty"`
+	// fields below match every laptop when they are left unset
+	Brands         []string `protobuf:"bytes,5,rep,name=brands,proto3" json:"brands,omitempty"`
+	Names          []string `protobuf:"bytes,6,rep,name=names,proto3" json:"names,omitempty"`
+	MinReleaseYear uint32   `protobuf:"varint,7,opt,name=min_release_year,json=minReleaseYear,proto3" json:"min_release_year,omitempty"`
+	MaxReleaseYear uint32   `protobuf:"varint,8,opt,name=max_release_year,json=maxReleaseYear,proto3" json:"max_release_year,omitempty"`
+	MinPriceUsd    float64  `protobuf:"fixed64,9,opt,name=min_price_usd,json=minPriceUsd,proto3" json:"min_price_usd,omitempty"`
+	// a laptop matches if one of its GPUs satisfies both
+	MinGpuMemory        *Memory            `protobuf:"bytes,10,opt,name=min_gpu_memory,json=minGpuMemory,proto3" json:"min_gpu_memory,omitempty"`
+	GpuBrand            string             `protobuf:"bytes,11,opt,name=gpu_brand,json=gpuBrand,proto3" json:"gpu_brand,omitempty"`
+	MinScreenSizeInch   float32            `protobuf:"fixed32,12,opt,name=min_screen_size_inch,json=minScreenSizeInch,proto3" json:"min_screen_size_inch,omitempty"`
+	MaxScreenSizeInch   float32            `protobuf:"fixed32,13,opt,name=max_screen_size_inch,json=maxScreenSizeInch,proto3" json:"max_screen_size_inch,omitempty"`
+	MinScreenResolution *Screen_Resolution `protobuf:"bytes,14,opt,name=min_screen_resolution,json=minScreenResolution,proto3" json:"min_screen_resolution,omitempty"`
+	ScreenPanel         Screen_Panel       `protobuf:"varint,15,opt,name=screen_panel,json=screenPanel,proto3,enum=example.pcbook.Screen_Panel" json:"screen_panel,omitempty"`
+	// when ssd_only is set, only SSD storages count towards min_storage and at least one is required
+	MinStorage *Memory `protobuf:"bytes,16,opt,name=min_storage,json=minStorage,proto3" json:"min_storage,omitempty"`
+	SsdOnly    bool    `protobuf:"varint,17,opt,name=ssd_only,json=ssdOnly,proto3" json:"ssd_only,omitempty"`
+	// laptops weighted in pounds are converted to kilograms
+	MaxWeightKg     float64         `protobuf:"fixed64,18,opt,name=max_weight_kg,json=maxWeightKg,proto3" json:"max_weight_kg,omitempty"`
+	KeyboardLayout  Keyboard_Layout `protobuf:"varint,19,opt,name=keyboard_layout,json=keyboardLayout,proto3,enum=example.pcbook.Keyboard_Layout" json:"keyboard_layout,omitempty"`
+	KeyboardBacklit *bool           `protobuf:"varint,20,opt,name=keyboard_backlit,json=keyboardBacklit,proto3,oneof" json:"keyboard_backlit,omitempty"`
+	// keeps the laptops with at least one unit available in the inventory, laptops cannot be watched by stock
+	InStockOnly bool `protobuf:"varint,21,opt,name=in_stock_only,json=inStockOnly,proto3" json:"in_stock_only,omitempty"`
 }
 
 func (x *Filter) Reset() {
@@ -91,6 +113,125 @@ func (x *Filter) GetMinRam() *Memory {
 	return nil
 }
 
+func (x *Filter) GetBrands() []string {
+	if x != nil {
+		return x.Brands
+	}
+	return nil
+}
+
+func (x *Filter) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *Filter) GetMinReleaseYear() uint32 {
+	if x != nil {
+		return x.MinReleaseYear
+	}
+	return 0
+}
+
+func (x *Filter) GetMaxReleaseYear() uint32 {
+	if x != nil {
+		return x.MaxReleaseYear
+	}
+	return 0
+}
+
+func (x *Filter) GetMinPriceUsd() float64 {
+	if x != nil {
+		return x.MinPriceUsd
+	}
+	return 0
+}
+
+func (x *Filter) GetMinGpuMemory() *Memory {
+	if x != nil {
+		return x.MinGpuMemory
+	}
+	return nil
+}
+
+func (x *Filter) GetGpuBrand() string {
+	if x != nil {
+		return x.GpuBrand
+	}
+	return ""
+}
+
+func (x *Filter) GetMinScreenSizeInch() float32 {
+	if x != nil {
+		return x.MinScreenSizeInch
+	}
+	return 0
+}
+
+func (x *Filter) GetMaxScreenSizeInch() float32 {
+	if x != nil {
+		return x.MaxScreenSizeInch
+	}
+	return 0
+}
+
+func (x *Filter) GetMinScreenResolution() *Screen_Resolution {
+	if x != nil {
+		return x.MinScreenResolution
+	}
+	return nil
+}
+
+func (x *Filter) GetScreenPanel() Screen_Panel {
+	if x != nil {
+		return x.ScreenPanel
+	}
+	return Screen_UNKNOWN
+}
+
+func (x *Filter) GetMinStorage() *Memory {
+	if x != nil {
+		return x.MinStorage
+	}
+	return nil
+}
+
+func (x *Filter) GetSsdOnly() bool {
+	if x != nil {
+		return x.SsdOnly
+	}
+	return false
+}
+
+func (x *Filter) GetMaxWeightKg() float64 {
+	if x != nil {
+		return x.MaxWeightKg
+	}
+	return 0
+}
+
+func (x *Filter) GetKeyboardLayout() Keyboard_Layout {
+	if x != nil {
+		return x.KeyboardLayout
+	}
+	return Keyboard_UNKNOWN
+}
+
+func (x *Filter) GetKeyboardBacklit() bool {
+	if x != nil && x.KeyboardBacklit != nil {
+		return *x.KeyboardBacklit
+	}
+	return false
+}
+
+func (x *Filter) GetInStockOnly() bool {
+	if x != nil {
+		return x.InStockOnly
+	}
+	return false
+}
+
 var File_proto_filter_message_proto protoreflect.FileDescriptor
 
 var file_proto_filter_message_proto_rawDesc = []byte{
@@ -98,17 +239,71 @@ var file_proto_filter_message_proto_rawDesc = []byte{
 	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x65, 0x78,
 	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x1a, 0x1a, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9f, 0x01, 0x0a, 0x06, 0x46, 0x69, 0x6c,
-	0x74, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65,
-	0x5f, 0x75, 0x73, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x50,
-	0x72, 0x69, 0x63, 0x65, 0x55, 0x73, 0x64, 0x12, 0x20, 0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x63,
-	0x70, 0x75, 0x5f, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d,
-	0x69, 0x6e, 0x43, 0x70, 0x75, 0x43, 0x6f, 0x72, 0x65, 0x12, 0x1e, 0x0a, 0x0b, 0x6d, 0x69, 0x6e,
-	0x5f, 0x63, 0x70, 0x75, 0x5f, 0x67, 0x68, 0x7a, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09,
-	0x6d, 0x69, 0x6e, 0x43, 0x70, 0x75, 0x47, 0x68, 0x7a, 0x12, 0x2f, 0x0a, 0x07, 0x6d, 0x69, 0x6e,
-	0x5f, 0x72, 0x61, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x65, 0x78, 0x61,
-	0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x4d, 0x65, 0x6d, 0x6f,
-	0x72, 0x79, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x52, 0x61, 0x6d, 0x42, 0x05, 0x5a, 0x03, 0x70, 0x62,
+	0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1a, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
+	0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6b, 0x65, 0x79, 0x62,
+	0x6f, 0x61, 0x72, 0x64, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x22, 0xc5, 0x07, 0x0a, 0x06, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x22, 0x0a,
+	0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x75, 0x73, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x50, 0x72, 0x69, 0x63, 0x65, 0x55, 0x73,
+	0x64, 0x12, 0x20, 0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x63, 0x70, 0x75, 0x5f, 0x63, 0x6f, 0x72,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d, 0x69, 0x6e, 0x43, 0x70, 0x75, 0x43,
+	0x6f, 0x72, 0x65, 0x12, 0x1e, 0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x5f, 0x63, 0x70, 0x75, 0x5f, 0x67,
+	0x68, 0x7a, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6d, 0x69, 0x6e, 0x43, 0x70, 0x75,
+	0x47, 0x68, 0x7a, 0x12, 0x2f, 0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x5f, 0x72, 0x61, 0x6d, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70,
+	0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x06, 0x6d, 0x69,
+	0x6e, 0x52, 0x61, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x72, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x62, 0x72, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x61, 0x6d,
+	0x65, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x6d, 0x69, 0x6e, 0x5f, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x5f, 0x79, 0x65, 0x61, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x6d, 0x69,
+	0x6e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x59, 0x65, 0x61, 0x72, 0x12, 0x28, 0x0a, 0x10,
+	0x6d, 0x61, 0x78, 0x5f, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x79, 0x65, 0x61, 0x72,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x6c, 0x65, 0x61,
+	0x73, 0x65, 0x59, 0x65, 0x61, 0x72, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x69, 0x6e, 0x5f, 0x70, 0x72,
+	0x69, 0x63, 0x65, 0x5f, 0x75, 0x73, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d,
+	0x69, 0x6e, 0x50, 0x72, 0x69, 0x63, 0x65, 0x55, 0x73, 0x64, 0x12, 0x3c, 0x0a, 0x0e, 0x6d, 0x69,
+	0x6e, 0x5f, 0x67, 0x70, 0x75, 0x5f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70, 0x63, 0x62,
+	0x6f, 0x6f, 0x6b, 0x2e, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x0c, 0x6d, 0x69, 0x6e, 0x47,
+	0x70, 0x75, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x67, 0x70, 0x75, 0x5f,
+	0x62, 0x72, 0x61, 0x6e, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x67, 0x70, 0x75,
+	0x42, 0x72, 0x61, 0x6e, 0x64, 0x12, 0x2f, 0x0a, 0x14, 0x6d, 0x69, 0x6e, 0x5f, 0x73, 0x63, 0x72,
+	0x65, 0x65, 0x6e, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x69, 0x6e, 0x63, 0x68, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x02, 0x52, 0x11, 0x6d, 0x69, 0x6e, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x53, 0x69,
+	0x7a, 0x65, 0x49, 0x6e, 0x63, 0x68, 0x12, 0x2f, 0x0a, 0x14, 0x6d, 0x61, 0x78, 0x5f, 0x73, 0x63,
+	0x72, 0x65, 0x65, 0x6e, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x69, 0x6e, 0x63, 0x68, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x02, 0x52, 0x11, 0x6d, 0x61, 0x78, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x53,
+	0x69, 0x7a, 0x65, 0x49, 0x6e, 0x63, 0x68, 0x12, 0x55, 0x0a, 0x15, 0x6d, 0x69, 0x6e, 0x5f, 0x73,
+	0x63, 0x72, 0x65, 0x65, 0x6e, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65,
+	0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x2e, 0x52,
+	0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x13, 0x6d, 0x69, 0x6e, 0x53, 0x63,
+	0x72, 0x65, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3f,
+	0x0a, 0x0c, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x5f, 0x70, 0x61, 0x6e, 0x65, 0x6c, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70,
+	0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x2e, 0x50, 0x61, 0x6e,
+	0x65, 0x6c, 0x52, 0x0b, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x50, 0x61, 0x6e, 0x65, 0x6c, 0x12,
+	0x37, 0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x5f, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x18, 0x10,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70,
+	0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x0a, 0x6d, 0x69,
+	0x6e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x73, 0x73, 0x64, 0x5f,
+	0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x73, 0x64, 0x4f,
+	0x6e, 0x6c, 0x79, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x77, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x5f, 0x6b, 0x67, 0x18, 0x12, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x57,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x4b, 0x67, 0x12, 0x48, 0x0a, 0x0f, 0x6b, 0x65, 0x79, 0x62, 0x6f,
+	0x61, 0x72, 0x64, 0x5f, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x1f, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f,
+	0x6b, 0x2e, 0x4b, 0x65, 0x79, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e, 0x4c, 0x61, 0x79, 0x6f, 0x75,
+	0x74, 0x52, 0x0e, 0x6b, 0x65, 0x79, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x4c, 0x61, 0x79, 0x6f, 0x75,
+	0x74, 0x12, 0x2e, 0x0a, 0x10, 0x6b, 0x65, 0x79, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x5f, 0x62, 0x61,
+	0x63, 0x6b, 0x6c, 0x69, 0x74, 0x18, 0x14, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x0f, 0x6b,
+	0x65, 0x79, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x42, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x74, 0x88, 0x01,
+	0x01, 0x12, 0x22, 0x0a, 0x0d, 0x69, 0x6e, 0x5f, 0x73, 0x74, 0x6f, 0x63, 0x6b, 0x5f, 0x6f, 0x6e,
+	0x6c, 0x79, 0x18, 0x15, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x6e, 0x53, 0x74, 0x6f, 0x63,
+	0x6b, 0x4f, 0x6e, 0x6c, 0x79, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x6b, 0x65, 0x79, 0x62, 0x6f, 0x61,
+	0x72, 0x64, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x6c, 0x69, 0x74, 0x42, 0x05, 0x5a, 0x03, 0x70, 0x62,
 	0x2f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
@@ -126,16 +321,24 @@ func file_proto_filter_message_proto_rawDescGZIP() []byte {
 
 var file_proto_filter_message_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
 var file_proto_filter_message_proto_goTypes = []interface{}{
-	(*Filter)(nil), // 0: example.pcbook.Filter
-	(*Memory)(nil), // 1: example.pcbook.Memory
+	(*Filter)(nil),            // 0: example.pcbook.Filter
+	(*Memory)(nil),            // 1: example.pcbook.Memory
+	(*Screen_Resolution)(nil), // 2: example.pcbook.Screen.Resolution
+	(Screen_Panel)(0),         // 3: example.pcbook.Screen.Panel
+	(Keyboard_Layout)(0),      // 4: example.pcbook.Keyboard.Layout
 }
 var file_proto_filter_message_proto_depIdxs = []int32{
 	1, // 0: example.pcbook.Filter.min_ram:type_name -> example.pcbook.Memory
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1, // 1: example.pcbook.Filter.min_gpu_memory:type_name -> example.pcbook.Memory
+	2, // 2: example.pcbook.Filter.min_screen_resolution:type_name -> example.pcbook.Screen.Resolution
+	3, // 3: example.pcbook.Filter.screen_panel:type_name -> example.pcbook.Screen.Panel
+	1, // 4: example.pcbook.Filter.min_storage:type_name -> example.pcbook.Memory
+	4, // 5: example.pcbook.Filter.keyboard_layout:type_name -> example.pcbook.Keyboard.Layout
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_proto_filter_message_proto_init() }
@@ -144,6 +347,8 @@ func file_proto_filter_message_proto_init() {
 		return
 	}
 	file_proto_memory_message_proto_init()
+	file_proto_screen_message_proto_init()
+	file_proto_keyboard_message_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_proto_filter_message_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Filter); i {
@@ -158,6 +363,7 @@ func file_proto_filter_message_proto_init() {
 			}
 		}
 	}
+	file_proto_filter_message_proto_msgTypes[0].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
@@ -0,0 +1,312 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v3.21.12
+// source: proto/journal_message.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RatingRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LaptopId string  `protobuf:"bytes,1,opt,name=laptop_id,json=laptopId,proto3" json:"laptop_id,omitempty"`
+	Count    uint32  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Sum      float64 `protobuf:"fixed64,3,opt,name=sum,proto3" json:"sum,omitempty"`
+}
+
+func (x *RatingRecord) Reset() {
+	*x = RatingRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_journal_message_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RatingRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RatingRecord) ProtoMessage() {}
+
+func (x *RatingRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_journal_message_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RatingRecord.ProtoReflect.Descriptor instead.
+func (*RatingRecord) Descriptor() ([]byte, []int) {
+	return file_proto_journal_message_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RatingRecord) GetLaptopId() string {
+	if x != nil {
+		return x.LaptopId
+	}
+	return ""
+}
+
+func (x *RatingRecord) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *RatingRecord) GetSum() float64 {
+	if x != nil {
+		return x.Sum
+	}
+	return 0
+}
+
+type JournalEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Entry:
+	//
+	//	*JournalEntry_SaveLaptop
+	//	*JournalEntry_UpdateLaptop
+	//	*JournalEntry_DeleteLaptopId
+	//	*JournalEntry_Rating
+	Entry isJournalEntry_Entry `protobuf_oneof:"entry"`
+}
+
+func (x *JournalEntry) Reset() {
+	*x = JournalEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_journal_message_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JournalEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JournalEntry) ProtoMessage() {}
+
+func (x *JournalEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_journal_message_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JournalEntry.ProtoReflect.Descriptor instead.
+func (*JournalEntry) Descriptor() ([]byte, []int) {
+	return file_proto_journal_message_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *JournalEntry) GetEntry() isJournalEntry_Entry {
+	if m != nil {
+		return m.Entry
+	}
+	return nil
+}
+
+func (x *JournalEntry) GetSaveLaptop() *Laptop {
+	if x, ok := x.GetEntry().(*JournalEntry_SaveLaptop); ok {
+		return x.SaveLaptop
+	}
+	return nil
+}
+
+func (x *JournalEntry) GetUpdateLaptop() *Laptop {
+	if x, ok := x.GetEntry().(*JournalEntry_UpdateLaptop); ok {
+		return x.UpdateLaptop
+	}
+	return nil
+}
+
+func (x *JournalEntry) GetDeleteLaptopId() string {
+	if x, ok := x.GetEntry().(*JournalEntry_DeleteLaptopId); ok {
+		return x.DeleteLaptopId
+	}
+	return ""
+}
+
+func (x *JournalEntry) GetRating() *RatingRecord {
+	if x, ok := x.GetEntry().(*JournalEntry_Rating); ok {
+		return x.Rating
+	}
+	return nil
+}
+
+type isJournalEntry_Entry interface {
+	isJournalEntry_Entry()
+}
+
+type JournalEntry_SaveLaptop struct {
+	SaveLaptop *Laptop `protobuf:"bytes,1,opt,name=save_laptop,json=saveLaptop,proto3,oneof"`
+}
+
+type JournalEntry_UpdateLaptop struct {
+	UpdateLaptop *Laptop `protobuf:"bytes,2,opt,name=update_laptop,json=updateLaptop,proto3,oneof"`
+}
+
+type JournalEntry_DeleteLaptopId struct {
+	DeleteLaptopId string `protobuf:"bytes,3,opt,name=delete_laptop_id,json=deleteLaptopId,proto3,oneof"`
+}
+
+type JournalEntry_Rating struct {
+	Rating *RatingRecord `protobuf:"bytes,4,opt,name=rating,proto3,oneof"`
+}
+
+func (*JournalEntry_SaveLaptop) isJournalEntry_Entry() {}
+
+func (*JournalEntry_UpdateLaptop) isJournalEntry_Entry() {}
+
+func (*JournalEntry_DeleteLaptopId) isJournalEntry_Entry() {}
+
+func (*JournalEntry_Rating) isJournalEntry_Entry() {}
+
+var File_proto_journal_message_proto protoreflect.FileDescriptor
+
+var file_proto_journal_message_proto_rawDesc = []byte{
+	0x0a, 0x1b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x5f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x65,
+	0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x1a, 0x1a, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x5f, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x53, 0x0a, 0x0c, 0x52, 0x61, 0x74,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x61, 0x70,
+	0x74, 0x6f, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61,
+	0x70, 0x74, 0x6f, 0x70, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03,
+	0x73, 0x75, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x73, 0x75, 0x6d, 0x22, 0xf5,
+	0x01, 0x0a, 0x0c, 0x4a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x39, 0x0a, 0x0b, 0x73, 0x61, 0x76, 0x65, 0x5f, 0x6c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70,
+	0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x48, 0x00, 0x52, 0x0a,
+	0x73, 0x61, 0x76, 0x65, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x12, 0x3d, 0x0a, 0x0d, 0x75, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x5f, 0x6c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x16, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x70, 0x63, 0x62, 0x6f,
+	0x6f, 0x6b, 0x2e, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x48, 0x00, 0x52, 0x0c, 0x75, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x12, 0x2a, 0x0a, 0x10, 0x64, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x5f, 0x6c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x61, 0x70,
+	0x74, 0x6f, 0x70, 0x49, 0x64, 0x12, 0x36, 0x0a, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e,
+	0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x48, 0x00, 0x52, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x42, 0x07, 0x0a,
+	0x05, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x42, 0x05, 0x5a, 0x03, 0x70, 0x62, 0x2f, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_journal_message_proto_rawDescOnce sync.Once
+	file_proto_journal_message_proto_rawDescData = file_proto_journal_message_proto_rawDesc
+)
+
+func file_proto_journal_message_proto_rawDescGZIP() []byte {
+	file_proto_journal_message_proto_rawDescOnce.Do(func() {
+		file_proto_journal_message_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_journal_message_proto_rawDescData)
+	})
+	return file_proto_journal_message_proto_rawDescData
+}
+
+var file_proto_journal_message_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_journal_message_proto_goTypes = []interface{}{
+	(*RatingRecord)(nil), // 0: example.pcbook.RatingRecord
+	(*JournalEntry)(nil), // 1: example.pcbook.JournalEntry
+	(*Laptop)(nil),       // 2: example.pcbook.Laptop
+}
+var file_proto_journal_message_proto_depIdxs = []int32{
+	2, // 0: example.pcbook.JournalEntry.save_laptop:type_name -> example.pcbook.Laptop
+	2, // 1: example.pcbook.JournalEntry.update_laptop:type_name -> example.pcbook.Laptop
+	0, // 2: example.pcbook.JournalEntry.rating:type_name -> example.pcbook.RatingRecord
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_proto_journal_message_proto_init() }
+func file_proto_journal_message_proto_init() {
+	if File_proto_journal_message_proto != nil {
+		return
+	}
+	file_proto_laptop_message_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_proto_journal_message_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RatingRecord); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_journal_message_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JournalEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_proto_journal_message_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*JournalEntry_SaveLaptop)(nil),
+		(*JournalEntry_UpdateLaptop)(nil),
+		(*JournalEntry_DeleteLaptopId)(nil),
+		(*JournalEntry_Rating)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_journal_message_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_journal_message_proto_goTypes,
+		DependencyIndexes: file_proto_journal_message_proto_depIdxs,
+		MessageInfos:      file_proto_journal_message_proto_msgTypes,
+	}.Build()
+	File_proto_journal_message_proto = out.File
+	file_proto_journal_message_proto_rawDesc = nil
+	file_proto_journal_message_proto_goTypes = nil
+	file_proto_journal_message_proto_depIdxs = nil
+}
@@ -19,10 +19,23 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	LaptopService_CreateLaptop_FullMethodName = "/example.pcbook.LaptopService/CreateLaptop"
-	LaptopService_SearchLaptop_FullMethodName = "/example.pcbook.LaptopService/SearchLaptop"
-	LaptopService_UploadImage_FullMethodName  = "/example.pcbook.LaptopService/UploadImage"
-	LaptopService_Ratelaptop_FullMethodName   = "/example.pcbook.LaptopService/Ratelaptop"
+	LaptopService_CreateLaptop_FullMethodName     = "/example.pcbook.LaptopService/CreateLaptop"
+	LaptopService_CreateLaptops_FullMethodName    = "/example.pcbook.LaptopService/CreateLaptops"
+	LaptopService_GetLaptop_FullMethodName        = "/example.pcbook.LaptopService/GetLaptop"
+	LaptopService_UpdateLaptop_FullMethodName     = "/example.pcbook.LaptopService/UpdateLaptop"
+	LaptopService_DeleteLaptop_FullMethodName     = "/example.pcbook.LaptopService/DeleteLaptop"
+	LaptopService_SearchLaptop_FullMethodName     = "/example.pcbook.LaptopService/SearchLaptop"
+	LaptopService_WatchLaptops_FullMethodName     = "/example.pcbook.LaptopService/WatchLaptops"
+	LaptopService_AggregateLaptops_FullMethodName = "/example.pcbook.LaptopService/AggregateLaptops"
+	LaptopService_ExportCatalog_FullMethodName    = "/example.pcbook.LaptopService/ExportCatalog"
+	LaptopService_ImportCatalog_FullMethodName    = "/example.pcbook.LaptopService/ImportCatalog"
+	LaptopService_UploadImage_FullMethodName      = "/example.pcbook.LaptopService/UploadImage"
+	LaptopService_DownloadImage_FullMethodName    = "/example.pcbook.LaptopService/DownloadImage"
+	LaptopService_ListLaptopImages_FullMethodName = "/example.pcbook.LaptopService/ListLaptopImages"
+	LaptopService_DeleteImage_FullMethodName      = "/example.pcbook.LaptopService/DeleteImage"
+	LaptopService_SetPrimaryImage_FullMethodName  = "/example.pcbook.LaptopService/SetPrimaryImage"
+	LaptopService_ReorderImages_FullMethodName    = "/example.pcbook.LaptopService/ReorderImages"
+	LaptopService_Ratelaptop_FullMethodName       = "/example.pcbook.LaptopService/Ratelaptop"
 )
 
 // LaptopServiceClient is the client API for LaptopService service.
@@ -30,8 +43,22 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type LaptopServiceClient interface {
 	CreateLaptop(ctx context.Context, in *CreateLaptopRequest, opts ...grpc.CallOption) (*CreateLaptopResponse, error)
+	CreateLaptops(ctx context.Context, opts ...grpc.CallOption) (LaptopService_CreateLaptopsClient, error)
+	GetLaptop(ctx context.Context, in *GetLaptopRequest, opts ...grpc.CallOption) (*GetLaptopResponse, error)
+	UpdateLaptop(ctx context.Context, in *UpdateLaptopRequest, opts ...grpc.CallOption) (*UpdateLaptopResponse, error)
+	DeleteLaptop(ctx context.Context, in *DeleteLaptopRequest, opts ...grpc.CallOption) (*DeleteLaptopResponse, error)
 	SearchLaptop(ctx context.Context, in *SearchLaptopRequest, opts ...grpc.CallOption) (LaptopService_SearchLaptopClient, error)
+	WatchLaptops(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (LaptopService_WatchLaptopsClient, error)
+	AggregateLaptops(ctx context.Context, in *AggregateLaptopsRequest, opts ...grpc.CallOption) (*AggregateLaptopsResponse, error)
+	ExportCatalog(ctx context.Context, in *ExportCatalogRequest, opts ...grpc.CallOption) (LaptopService_ExportCatalogClient, error)
+	ImportCatalog(ctx context.Context, opts ...grpc.CallOption) (LaptopService_ImportCatalogClient, error)
 	UploadImage(ctx context.Context, opts ...grpc.CallOption) (LaptopService_UploadImageClient, error)
+	// the REST endpoint sends the raw image instead of JSON messages, see DownloadImageHandler
+	DownloadImage(ctx context.Context, in *DownloadImageRequest, opts ...grpc.CallOption) (LaptopService_DownloadImageClient, error)
+	ListLaptopImages(ctx context.Context, in *ListLaptopImagesRequest, opts ...grpc.CallOption) (*ListLaptopImagesResponse, error)
+	DeleteImage(ctx context.Context, in *DeleteImageRequest, opts ...grpc.CallOption) (*DeleteImageResponse, error)
+	SetPrimaryImage(ctx context.Context, in *SetPrimaryImageRequest, opts ...grpc.CallOption) (*SetPrimaryImageResponse, error)
+	ReorderImages(ctx context.Context, in *ReorderImagesRequest, opts ...grpc.CallOption) (*ReorderImagesResponse, error)
 	Ratelaptop(ctx context.Context, opts ...grpc.CallOption) (LaptopService_RatelaptopClient, error)
 }
 
@@ -52,8 +79,69 @@ func (c *laptopServiceClient) CreateLaptop(ctx context.Context, in *CreateLaptop
 	return out, nil
 }
 
+func (c *laptopServiceClient) CreateLaptops(ctx context.Context, opts ...grpc.CallOption) (LaptopService_CreateLaptopsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[0], LaptopService_CreateLaptops_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &laptopServiceCreateLaptopsClient{stream}
+	return x, nil
+}
+
+type LaptopService_CreateLaptopsClient interface {
+	Send(*CreateLaptopRequest) error
+	CloseAndRecv() (*CreateLaptopsResponse, error)
+	grpc.ClientStream
+}
+
+type laptopServiceCreateLaptopsClient struct {
+	grpc.ClientStream
+}
+
+func (x *laptopServiceCreateLaptopsClient) Send(m *CreateLaptopRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *laptopServiceCreateLaptopsClient) CloseAndRecv() (*CreateLaptopsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(CreateLaptopsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *laptopServiceClient) GetLaptop(ctx context.Context, in *GetLaptopRequest, opts ...grpc.CallOption) (*GetLaptopResponse, error) {
+	out := new(GetLaptopResponse)
+	err := c.cc.Invoke(ctx, LaptopService_GetLaptop_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) UpdateLaptop(ctx context.Context, in *UpdateLaptopRequest, opts ...grpc.CallOption) (*UpdateLaptopResponse, error) {
+	out := new(UpdateLaptopResponse)
+	err := c.cc.Invoke(ctx, LaptopService_UpdateLaptop_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) DeleteLaptop(ctx context.Context, in *DeleteLaptopRequest, opts ...grpc.CallOption) (*DeleteLaptopResponse, error) {
+	out := new(DeleteLaptopResponse)
+	err := c.cc.Invoke(ctx, LaptopService_DeleteLaptop_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *laptopServiceClient) SearchLaptop(ctx context.Context, in *SearchLaptopRequest, opts ...grpc.CallOption) (LaptopService_SearchLaptopClient, error) {
-	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[0], LaptopService_SearchLaptop_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[1], LaptopService_SearchLaptop_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -84,8 +172,115 @@ func (x *laptopServiceSearchLaptopClient) Recv() (*SearchLaptopResponse, error)
 	return m, nil
 }
 
+func (c *laptopServiceClient) WatchLaptops(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (LaptopService_WatchLaptopsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[2], LaptopService_WatchLaptops_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &laptopServiceWatchLaptopsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LaptopService_WatchLaptopsClient interface {
+	Recv() (*WatchResponse, error)
+	grpc.ClientStream
+}
+
+type laptopServiceWatchLaptopsClient struct {
+	grpc.ClientStream
+}
+
+func (x *laptopServiceWatchLaptopsClient) Recv() (*WatchResponse, error) {
+	m := new(WatchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *laptopServiceClient) AggregateLaptops(ctx context.Context, in *AggregateLaptopsRequest, opts ...grpc.CallOption) (*AggregateLaptopsResponse, error) {
+	out := new(AggregateLaptopsResponse)
+	err := c.cc.Invoke(ctx, LaptopService_AggregateLaptops_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) ExportCatalog(ctx context.Context, in *ExportCatalogRequest, opts ...grpc.CallOption) (LaptopService_ExportCatalogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[3], LaptopService_ExportCatalog_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &laptopServiceExportCatalogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LaptopService_ExportCatalogClient interface {
+	Recv() (*CatalogRecord, error)
+	grpc.ClientStream
+}
+
+type laptopServiceExportCatalogClient struct {
+	grpc.ClientStream
+}
+
+func (x *laptopServiceExportCatalogClient) Recv() (*CatalogRecord, error) {
+	m := new(CatalogRecord)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *laptopServiceClient) ImportCatalog(ctx context.Context, opts ...grpc.CallOption) (LaptopService_ImportCatalogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[4], LaptopService_ImportCatalog_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &laptopServiceImportCatalogClient{stream}
+	return x, nil
+}
+
+type LaptopService_ImportCatalogClient interface {
+	Send(*ImportCatalogRequest) error
+	CloseAndRecv() (*ImportCatalogResponse, error)
+	grpc.ClientStream
+}
+
+type laptopServiceImportCatalogClient struct {
+	grpc.ClientStream
+}
+
+func (x *laptopServiceImportCatalogClient) Send(m *ImportCatalogRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *laptopServiceImportCatalogClient) CloseAndRecv() (*ImportCatalogResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportCatalogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *laptopServiceClient) UploadImage(ctx context.Context, opts ...grpc.CallOption) (LaptopService_UploadImageClient, error) {
-	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[1], LaptopService_UploadImage_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[5], LaptopService_UploadImage_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -118,8 +313,76 @@ func (x *laptopServiceUploadImageClient) CloseAndRecv() (*UploadImageResponse, e
 	return m, nil
 }
 
+func (c *laptopServiceClient) DownloadImage(ctx context.Context, in *DownloadImageRequest, opts ...grpc.CallOption) (LaptopService_DownloadImageClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[6], LaptopService_DownloadImage_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &laptopServiceDownloadImageClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LaptopService_DownloadImageClient interface {
+	Recv() (*DownloadImageResponse, error)
+	grpc.ClientStream
+}
+
+type laptopServiceDownloadImageClient struct {
+	grpc.ClientStream
+}
+
+func (x *laptopServiceDownloadImageClient) Recv() (*DownloadImageResponse, error) {
+	m := new(DownloadImageResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *laptopServiceClient) ListLaptopImages(ctx context.Context, in *ListLaptopImagesRequest, opts ...grpc.CallOption) (*ListLaptopImagesResponse, error) {
+	out := new(ListLaptopImagesResponse)
+	err := c.cc.Invoke(ctx, LaptopService_ListLaptopImages_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) DeleteImage(ctx context.Context, in *DeleteImageRequest, opts ...grpc.CallOption) (*DeleteImageResponse, error) {
+	out := new(DeleteImageResponse)
+	err := c.cc.Invoke(ctx, LaptopService_DeleteImage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) SetPrimaryImage(ctx context.Context, in *SetPrimaryImageRequest, opts ...grpc.CallOption) (*SetPrimaryImageResponse, error) {
+	out := new(SetPrimaryImageResponse)
+	err := c.cc.Invoke(ctx, LaptopService_SetPrimaryImage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) ReorderImages(ctx context.Context, in *ReorderImagesRequest, opts ...grpc.CallOption) (*ReorderImagesResponse, error) {
+	out := new(ReorderImagesResponse)
+	err := c.cc.Invoke(ctx, LaptopService_ReorderImages_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *laptopServiceClient) Ratelaptop(ctx context.Context, opts ...grpc.CallOption) (LaptopService_RatelaptopClient, error) {
-	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[2], LaptopService_Ratelaptop_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[7], LaptopService_Ratelaptop_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -154,8 +417,22 @@ func (x *laptopServiceRatelaptopClient) Recv() (*RateLaptopResponse, error) {
 // for forward compatibility
 type LaptopServiceServer interface {
 	CreateLaptop(context.Context, *CreateLaptopRequest) (*CreateLaptopResponse, error)
+	CreateLaptops(LaptopService_CreateLaptopsServer) error
+	GetLaptop(context.Context, *GetLaptopRequest) (*GetLaptopResponse, error)
+	UpdateLaptop(context.Context, *UpdateLaptopRequest) (*UpdateLaptopResponse, error)
+	DeleteLaptop(context.Context, *DeleteLaptopRequest) (*DeleteLaptopResponse, error)
 	SearchLaptop(*SearchLaptopRequest, LaptopService_SearchLaptopServer) error
+	WatchLaptops(*WatchRequest, LaptopService_WatchLaptopsServer) error
+	AggregateLaptops(context.Context, *AggregateLaptopsRequest) (*AggregateLaptopsResponse, error)
+	ExportCatalog(*ExportCatalogRequest, LaptopService_ExportCatalogServer) error
+	ImportCatalog(LaptopService_ImportCatalogServer) error
 	UploadImage(LaptopService_UploadImageServer) error
+	// the REST endpoint sends the raw image instead of JSON messages, see DownloadImageHandler
+	DownloadImage(*DownloadImageRequest, LaptopService_DownloadImageServer) error
+	ListLaptopImages(context.Context, *ListLaptopImagesRequest) (*ListLaptopImagesResponse, error)
+	DeleteImage(context.Context, *DeleteImageRequest) (*DeleteImageResponse, error)
+	SetPrimaryImage(context.Context, *SetPrimaryImageRequest) (*SetPrimaryImageResponse, error)
+	ReorderImages(context.Context, *ReorderImagesRequest) (*ReorderImagesResponse, error)
 	Ratelaptop(LaptopService_RatelaptopServer) error
 	mustEmbedUnimplementedLaptopServiceServer()
 }
@@ -167,12 +444,51 @@ type UnimplementedLaptopServiceServer struct {
 func (UnimplementedLaptopServiceServer) CreateLaptop(context.Context, *CreateLaptopRequest) (*CreateLaptopResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateLaptop not implemented")
 }
+func (UnimplementedLaptopServiceServer) CreateLaptops(LaptopService_CreateLaptopsServer) error {
+	return status.Errorf(codes.Unimplemented, "method CreateLaptops not implemented")
+}
+func (UnimplementedLaptopServiceServer) GetLaptop(context.Context, *GetLaptopRequest) (*GetLaptopResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLaptop not implemented")
+}
+func (UnimplementedLaptopServiceServer) UpdateLaptop(context.Context, *UpdateLaptopRequest) (*UpdateLaptopResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLaptop not implemented")
+}
+func (UnimplementedLaptopServiceServer) DeleteLaptop(context.Context, *DeleteLaptopRequest) (*DeleteLaptopResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLaptop not implemented")
+}
 func (UnimplementedLaptopServiceServer) SearchLaptop(*SearchLaptopRequest, LaptopService_SearchLaptopServer) error {
 	return status.Errorf(codes.Unimplemented, "method SearchLaptop not implemented")
 }
+func (UnimplementedLaptopServiceServer) WatchLaptops(*WatchRequest, LaptopService_WatchLaptopsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchLaptops not implemented")
+}
+func (UnimplementedLaptopServiceServer) AggregateLaptops(context.Context, *AggregateLaptopsRequest) (*AggregateLaptopsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AggregateLaptops not implemented")
+}
+func (UnimplementedLaptopServiceServer) ExportCatalog(*ExportCatalogRequest, LaptopService_ExportCatalogServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportCatalog not implemented")
+}
+func (UnimplementedLaptopServiceServer) ImportCatalog(LaptopService_ImportCatalogServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportCatalog not implemented")
+}
 func (UnimplementedLaptopServiceServer) UploadImage(LaptopService_UploadImageServer) error {
 	return status.Errorf(codes.Unimplemented, "method UploadImage not implemented")
 }
+func (UnimplementedLaptopServiceServer) DownloadImage(*DownloadImageRequest, LaptopService_DownloadImageServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadImage not implemented")
+}
+func (UnimplementedLaptopServiceServer) ListLaptopImages(context.Context, *ListLaptopImagesRequest) (*ListLaptopImagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLaptopImages not implemented")
+}
+func (UnimplementedLaptopServiceServer) DeleteImage(context.Context, *DeleteImageRequest) (*DeleteImageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteImage not implemented")
+}
+func (UnimplementedLaptopServiceServer) SetPrimaryImage(context.Context, *SetPrimaryImageRequest) (*SetPrimaryImageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPrimaryImage not implemented")
+}
+func (UnimplementedLaptopServiceServer) ReorderImages(context.Context, *ReorderImagesRequest) (*ReorderImagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReorderImages not implemented")
+}
 func (UnimplementedLaptopServiceServer) Ratelaptop(LaptopService_RatelaptopServer) error {
 	return status.Errorf(codes.Unimplemented, "method Ratelaptop not implemented")
 }
@@ -207,6 +523,86 @@ func _LaptopService_CreateLaptop_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LaptopService_CreateLaptops_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LaptopServiceServer).CreateLaptops(&laptopServiceCreateLaptopsServer{stream})
+}
+
+type LaptopService_CreateLaptopsServer interface {
+	SendAndClose(*CreateLaptopsResponse) error
+	Recv() (*CreateLaptopRequest, error)
+	grpc.ServerStream
+}
+
+type laptopServiceCreateLaptopsServer struct {
+	grpc.ServerStream
+}
+
+func (x *laptopServiceCreateLaptopsServer) SendAndClose(m *CreateLaptopsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *laptopServiceCreateLaptopsServer) Recv() (*CreateLaptopRequest, error) {
+	m := new(CreateLaptopRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _LaptopService_GetLaptop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLaptopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).GetLaptop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LaptopService_GetLaptop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).GetLaptop(ctx, req.(*GetLaptopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_UpdateLaptop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLaptopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).UpdateLaptop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LaptopService_UpdateLaptop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).UpdateLaptop(ctx, req.(*UpdateLaptopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_DeleteLaptop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLaptopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).DeleteLaptop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LaptopService_DeleteLaptop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).DeleteLaptop(ctx, req.(*DeleteLaptopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _LaptopService_SearchLaptop_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(SearchLaptopRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -228,6 +624,92 @@ func (x *laptopServiceSearchLaptopServer) Send(m *SearchLaptopResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _LaptopService_WatchLaptops_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LaptopServiceServer).WatchLaptops(m, &laptopServiceWatchLaptopsServer{stream})
+}
+
+type LaptopService_WatchLaptopsServer interface {
+	Send(*WatchResponse) error
+	grpc.ServerStream
+}
+
+type laptopServiceWatchLaptopsServer struct {
+	grpc.ServerStream
+}
+
+func (x *laptopServiceWatchLaptopsServer) Send(m *WatchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LaptopService_AggregateLaptops_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AggregateLaptopsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).AggregateLaptops(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LaptopService_AggregateLaptops_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).AggregateLaptops(ctx, req.(*AggregateLaptopsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_ExportCatalog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportCatalogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LaptopServiceServer).ExportCatalog(m, &laptopServiceExportCatalogServer{stream})
+}
+
+type LaptopService_ExportCatalogServer interface {
+	Send(*CatalogRecord) error
+	grpc.ServerStream
+}
+
+type laptopServiceExportCatalogServer struct {
+	grpc.ServerStream
+}
+
+func (x *laptopServiceExportCatalogServer) Send(m *CatalogRecord) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LaptopService_ImportCatalog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LaptopServiceServer).ImportCatalog(&laptopServiceImportCatalogServer{stream})
+}
+
+type LaptopService_ImportCatalogServer interface {
+	SendAndClose(*ImportCatalogResponse) error
+	Recv() (*ImportCatalogRequest, error)
+	grpc.ServerStream
+}
+
+type laptopServiceImportCatalogServer struct {
+	grpc.ServerStream
+}
+
+func (x *laptopServiceImportCatalogServer) SendAndClose(m *ImportCatalogResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *laptopServiceImportCatalogServer) Recv() (*ImportCatalogRequest, error) {
+	m := new(ImportCatalogRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _LaptopService_UploadImage_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(LaptopServiceServer).UploadImage(&laptopServiceUploadImageServer{stream})
 }
@@ -254,6 +736,99 @@ func (x *laptopServiceUploadImageServer) Recv() (*UploadImageRequest, error) {
 	return m, nil
 }
 
+func _LaptopService_DownloadImage_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadImageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LaptopServiceServer).DownloadImage(m, &laptopServiceDownloadImageServer{stream})
+}
+
+type LaptopService_DownloadImageServer interface {
+	Send(*DownloadImageResponse) error
+	grpc.ServerStream
+}
+
+type laptopServiceDownloadImageServer struct {
+	grpc.ServerStream
+}
+
+func (x *laptopServiceDownloadImageServer) Send(m *DownloadImageResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LaptopService_ListLaptopImages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLaptopImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).ListLaptopImages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LaptopService_ListLaptopImages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).ListLaptopImages(ctx, req.(*ListLaptopImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_DeleteImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).DeleteImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LaptopService_DeleteImage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).DeleteImage(ctx, req.(*DeleteImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_SetPrimaryImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPrimaryImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).SetPrimaryImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LaptopService_SetPrimaryImage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).SetPrimaryImage(ctx, req.(*SetPrimaryImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_ReorderImages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).ReorderImages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LaptopService_ReorderImages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).ReorderImages(ctx, req.(*ReorderImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _LaptopService_Ratelaptop_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(LaptopServiceServer).Ratelaptop(&laptopServiceRatelaptopServer{stream})
 }
@@ -291,18 +866,75 @@ var LaptopService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateLaptop",
 			Handler:    _LaptopService_CreateLaptop_Handler,
 		},
+		{
+			MethodName: "GetLaptop",
+			Handler:    _LaptopService_GetLaptop_Handler,
+		},
+		{
+			MethodName: "UpdateLaptop",
+			Handler:    _LaptopService_UpdateLaptop_Handler,
+		},
+		{
+			MethodName: "DeleteLaptop",
+			Handler:    _LaptopService_DeleteLaptop_Handler,
+		},
+		{
+			MethodName: "AggregateLaptops",
+			Handler:    _LaptopService_AggregateLaptops_Handler,
+		},
+		{
+			MethodName: "ListLaptopImages",
+			Handler:    _LaptopService_ListLaptopImages_Handler,
+		},
+		{
+			MethodName: "DeleteImage",
+			Handler:    _LaptopService_DeleteImage_Handler,
+		},
+		{
+			MethodName: "SetPrimaryImage",
+			Handler:    _LaptopService_SetPrimaryImage_Handler,
+		},
+		{
+			MethodName: "ReorderImages",
+			Handler:    _LaptopService_ReorderImages_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CreateLaptops",
+			Handler:       _LaptopService_CreateLaptops_Handler,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "SearchLaptop",
 			Handler:       _LaptopService_SearchLaptop_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchLaptops",
+			Handler:       _LaptopService_WatchLaptops_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportCatalog",
+			Handler:       _LaptopService_ExportCatalog_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportCatalog",
+			Handler:       _LaptopService_ImportCatalog_Handler,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "UploadImage",
 			Handler:       _LaptopService_UploadImage_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "DownloadImage",
+			Handler:       _LaptopService_DownloadImage_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "Ratelaptop",
 			Handler:       _LaptopService_Ratelaptop_Handler,
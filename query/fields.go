@@ -0,0 +1,318 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"example.com/pcbook/memsize"
+	"example.com/pcbook/pb"
+)
+
+type field struct {
+	multiple bool
+	apply    func(filter *pb.Filter, t term) error
+}
+
+var fields = map[string]field{
+	"brand": stringSet(func(filter *pb.Filter) *[]string { return &filter.Brands }),
+	"name":  stringSet(func(filter *pb.Filter) *[]string { return &filter.Names }),
+	"price": floatRange(
+		func(filter *pb.Filter) *float64 { return &filter.MinPriceUsd },
+		func(filter *pb.Filter) *float64 { return &filter.MaxPriceUsd },
+	),
+	"year": uintRange(
+		func(filter *pb.Filter) *uint32 { return &filter.MinReleaseYear },
+		func(filter *pb.Filter) *uint32 { return &filter.MaxReleaseYear },
+	),
+	"cpu.cores":  uintRange(func(filter *pb.Filter) *uint32 { return &filter.MinCpuCore }, nil),
+	"cpu.ghz":    floatRange(func(filter *pb.Filter) *float64 { return &filter.MinCpuGhz }, nil),
+	"ram":        memoryMin(func(filter *pb.Filter) **pb.Memory { return &filter.MinRam }),
+	"gpu.memory": memoryMin(func(filter *pb.Filter) **pb.Memory { return &filter.MinGpuMemory }),
+	"gpu.brand":  stringValue(func(filter *pb.Filter, s string) { filter.GpuBrand = s }),
+	"screen.size": float32Range(
+		func(filter *pb.Filter) *float32 { return &filter.MinScreenSizeInch },
+		func(filter *pb.Filter) *float32 { return &filter.MaxScreenSizeInch },
+	),
+	"screen.resolution": {apply: applyResolution},
+	"screen.panel":      screenPanel,
+	"panel":             screenPanel,
+	"storage":           memoryMin(func(filter *pb.Filter) **pb.Memory { return &filter.MinStorage }),
+	"ssd":               boolValue(func(filter *pb.Filter, b bool) { filter.SsdOnly = b }),
+	"in_stock":          boolValue(func(filter *pb.Filter, b bool) { filter.InStockOnly = b }),
+	"weight":            {apply: applyWeight},
+	"keyboard.layout":   keyboardLayout,
+	"layout":            keyboardLayout,
+	"keyboard.backlit":  keyboardBacklit,
+	"backlit":           keyboardBacklit,
+}
+
+var screenPanel = enumValue(pb.Screen_Panel_value, func(filter *pb.Filter, n int32) {
+	filter.ScreenPanel = pb.Screen_Panel(n)
+})
+
+var keyboardLayout = enumValue(pb.Keyboard_Layout_value, func(filter *pb.Filter, n int32) {
+	filter.KeyboardLayout = pb.Keyboard_Layout(n)
+})
+
+var keyboardBacklit = boolValue(func(filter *pb.Filter, b bool) {
+	filter.KeyboardBacklit = &b
+})
+
+func valueError(v value, format string, a ...interface{}) error {
+	return &SyntaxError{Column: v.column, Msg: fmt.Sprintf(format, a...)}
+}
+
+func equalOnly(t term) error {
+	if t.op != opEqual {
+		return t.errorf("%s only supports the %q operator", t.key, ":")
+	}
+	return nil
+}
+
+// limits tells which bounds a term sets, equality sets both of them
+func limits(t term, hasMin bool, hasMax bool) (setMin bool, setMax bool, strict bool, err error) {
+	switch t.op {
+	case opEqual:
+		setMin, setMax = true, true
+	case opGreater:
+		setMin, strict = true, true
+	case opGreaterEqual:
+		setMin = true
+	case opLess:
+		setMax, strict = true, true
+	case opLessEqual:
+		setMax = true
+	}
+	if setMin && !hasMin || setMax && !hasMax {
+		return false, false, false, t.errorf("operator %q is not supported for %s", t.op, t.key)
+	}
+	return setMin, setMax, strict, nil
+}
+
+func stringSet(set func(filter *pb.Filter) *[]string) field {
+	return field{
+		multiple: true,
+		apply: func(filter *pb.Filter, t term) error {
+			err := equalOnly(t)
+			if err != nil {
+				return err
+			}
+			list := set(filter)
+			for _, v := range t.values {
+				*list = append(*list, v.text)
+			}
+			return nil
+		},
+	}
+}
+
+func stringValue(set func(filter *pb.Filter, s string)) field {
+	return field{
+		apply: func(filter *pb.Filter, t term) error {
+			err := equalOnly(t)
+			if err != nil {
+				return err
+			}
+			set(filter, t.values[0].text)
+			return nil
+		},
+	}
+}
+
+func boolValue(set func(filter *pb.Filter, b bool)) field {
+	return field{
+		apply: func(filter *pb.Filter, t term) error {
+			err := equalOnly(t)
+			if err != nil {
+				return err
+			}
+			v := t.values[0]
+			b, err := strconv.ParseBool(v.text)
+			if err != nil {
+				return valueError(v, "%s must be true or false", t.key)
+			}
+			set(filter, b)
+			return nil
+		},
+	}
+}
+
+func enumValue(values map[string]int32, set func(filter *pb.Filter, n int32)) field {
+	return field{
+		apply: func(filter *pb.Filter, t term) error {
+			err := equalOnly(t)
+			if err != nil {
+				return err
+			}
+			v := t.values[0]
+			n, ok := values[strings.ToUpper(v.text)]
+			if !ok || n == 0 {
+				return valueError(v, "unknown %s %q", t.key, v.text)
+			}
+			set(filter, n)
+			return nil
+		},
+	}
+}
+
+func floatRange(min func(filter *pb.Filter) *float64, max func(filter *pb.Filter) *float64) field {
+	return field{
+		apply: func(filter *pb.Filter, t term) error {
+			setMin, setMax, strict, err := limits(t, min != nil, max != nil)
+			if err != nil {
+				return err
+			}
+			v := t.values[0]
+			x, err := strconv.ParseFloat(v.text, 64)
+			if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+				return valueError(v, "invalid number %q", v.text)
+			}
+			if setMin {
+				*min(filter) = x
+				if strict {
+					*min(filter) = math.Nextafter(x, math.Inf(1))
+				}
+			}
+			if setMax {
+				*max(filter) = x
+				if strict {
+					*max(filter) = math.Nextafter(x, math.Inf(-1))
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func float32Range(min func(filter *pb.Filter) *float32, max func(filter *pb.Filter) *float32) field {
+	return field{
+		apply: func(filter *pb.Filter, t term) error {
+			setMin, setMax, strict, err := limits(t, min != nil, max != nil)
+			if err != nil {
+				return err
+			}
+			v := t.values[0]
+			x, err := strconv.ParseFloat(v.text, 32)
+			if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+				return valueError(v, "invalid number %q", v.text)
+			}
+			if setMin {
+				*min(filter) = float32(x)
+				if strict {
+					*min(filter) = math.Nextafter32(float32(x), float32(math.Inf(1)))
+				}
+			}
+			if setMax {
+				*max(filter) = float32(x)
+				if strict {
+					*max(filter) = math.Nextafter32(float32(x), float32(math.Inf(-1)))
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func uintRange(min func(filter *pb.Filter) *uint32, max func(filter *pb.Filter) *uint32) field {
+	return field{
+		apply: func(filter *pb.Filter, t term) error {
+			setMin, setMax, strict, err := limits(t, min != nil, max != nil)
+			if err != nil {
+				return err
+			}
+			v := t.values[0]
+			x, err := strconv.ParseUint(v.text, 10, 32)
+			if err != nil {
+				return valueError(v, "invalid whole number %q", v.text)
+			}
+			if setMin {
+				*min(filter) = uint32(x)
+				if strict {
+					if x == math.MaxUint32 {
+						return valueError(v, "%s is out of range", v.text)
+					}
+					*min(filter) = uint32(x + 1)
+				}
+			}
+			if setMax {
+				// a zero maximum means no maximum
+				if x == 0 || strict && x == 1 {
+					return valueError(v, "%s must be above 0", t.key)
+				}
+				*max(filter) = uint32(x)
+				if strict {
+					*max(filter) = uint32(x - 1)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func memoryMin(min func(filter *pb.Filter) **pb.Memory) field {
+	return field{
+		apply: func(filter *pb.Filter, t term) error {
+			if t.op != opGreaterEqual {
+				return t.errorf("%s only supports the %q operator", t.key, ">=")
+			}
+			memory, err := parseMemory(t.values[0])
+			if err != nil {
+				return err
+			}
+			*min(filter) = memory
+			return nil
+		},
+	}
+}
+
+func parseMemory(v value) (*pb.Memory, error) {
+	memory, err := memsize.Parse(v.text)
+	if parseErr, ok := err.(*memsize.ParseError); ok {
+		return nil, &SyntaxError{Column: v.column + parseErr.Offset, Msg: parseErr.Error()}
+	}
+	return memory, err
+}
+
+func applyResolution(filter *pb.Filter, t term) error {
+	if t.op != opGreaterEqual {
+		return t.errorf("%s only supports the %q operator", t.key, ">=")
+	}
+	v := t.values[0]
+	width, height, ok := strings.Cut(strings.ToLower(v.text), "x")
+	w, err1 := strconv.ParseUint(width, 10, 32)
+	h, err2 := strconv.ParseUint(height, 10, 32)
+	if !ok || err1 != nil || err2 != nil {
+		return valueError(v, "invalid resolution %q, expected WIDTHxHEIGHT", v.text)
+	}
+	filter.MinScreenResolution = &pb.Screen_Resolution{
+		Width:  uint32(w),
+		Height: uint32(h),
+	}
+	return nil
+}
+
+func applyWeight(filter *pb.Filter, t term) error {
+	if t.op != opLess && t.op != opLessEqual {
+		return t.errorf("%s only supports the %q and %q operators", t.key, opLess, opLessEqual)
+	}
+	v := t.values[0]
+	text := strings.ToLower(v.text)
+	factor := 1.0
+	if strings.HasSuffix(text, "lb") {
+		text = strings.TrimSuffix(text, "lb")
+		factor = memsize.KgPerLb
+	} else {
+		text = strings.TrimSuffix(text, "kg")
+	}
+	x, err := strconv.ParseFloat(text, 64)
+	if err != nil || x <= 0 || math.IsInf(x, 0) {
+		return valueError(v, "invalid weight %q", v.text)
+	}
+	filter.MaxWeightKg = x * factor
+	if t.op == opLess {
+		filter.MaxWeightKg = math.Nextafter(filter.MaxWeightKg, 0)
+	}
+	return nil
+}
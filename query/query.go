@@ -0,0 +1,173 @@
+// Package query compiles human-friendly search queries into a pb.Filter.
+//
+// A query is a list of terms separated by spaces, each made of a key, an operator and a value:
+//
+//	brand:Dell,Lenovo price<2000 ram>=16GB cpu.cores>=6 panel:OLED name:"Thinkpad X1"
+//
+// The operators are ":" and "=" for equality, and "<", "<=", ">", ">=" for ranges.
+// With ":" and "=" a comma-separated list of values may be given for keys that accept a set.
+// Values containing spaces or commas are written in double quotes.
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/pcbook/pb"
+)
+
+// SyntaxError reports a problem with a query at a 1-based column
+type SyntaxError struct {
+	Column int
+	Msg    string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", err.Column, err.Msg)
+}
+
+type operator string
+
+const (
+	opEqual        operator = "="
+	opLess         operator = "<"
+	opLessEqual    operator = "<="
+	opGreater      operator = ">"
+	opGreaterEqual operator = ">="
+)
+
+type value struct {
+	text   string
+	column int
+}
+
+type term struct {
+	key    string
+	op     operator
+	values []value
+	column int
+}
+
+func (t term) errorf(format string, a ...interface{}) error {
+	return &SyntaxError{Column: t.column, Msg: fmt.Sprintf(format, a...)}
+}
+
+// Parse compiles query into a filter, the fields that the query doesn't mention are left unset.
+func Parse(query string) (*pb.Filter, error) {
+	terms, err := scan(query)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &pb.Filter{}
+	for _, t := range terms {
+		field, ok := fields[t.key]
+		if !ok {
+			return nil, t.errorf("unknown key %q", t.key)
+		}
+		if len(t.values) > 1 && !field.multiple {
+			return nil, t.errorf("%s accepts a single value", t.key)
+		}
+		err := field.apply(filter, t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+func scan(query string) ([]term, error) {
+	var terms []term
+	i := 0
+	for {
+		for i < len(query) && query[i] == ' ' {
+			i++
+		}
+		if i == len(query) {
+			return terms, nil
+		}
+
+		t := term{column: i + 1}
+		start := i
+		for i < len(query) && isKeyChar(query[i]) {
+			i++
+		}
+		if i == start {
+			return nil, &SyntaxError{Column: i + 1, Msg: fmt.Sprintf("expected a key, found %q", query[i])}
+		}
+		t.key = strings.ToLower(query[start:i])
+
+		op, n := scanOperator(query[i:])
+		if n == 0 {
+			return nil, &SyntaxError{Column: i + 1, Msg: fmt.Sprintf("expected an operator after %q", t.key)}
+		}
+		t.op = op
+		i += n
+
+		for {
+			v, end, err := scanValue(query, i)
+			if err != nil {
+				return nil, err
+			}
+			t.values = append(t.values, v)
+			i = end
+			if i < len(query) && query[i] == ',' {
+				if t.op != opEqual {
+					return nil, &SyntaxError{Column: i + 1, Msg: fmt.Sprintf("a list of values needs the %q operator", ":")}
+				}
+				i++
+				continue
+			}
+			break
+		}
+
+		if i < len(query) && query[i] != ' ' {
+			return nil, &SyntaxError{Column: i + 1, Msg: fmt.Sprintf("unexpected %q", query[i])}
+		}
+		terms = append(terms, t)
+	}
+}
+
+func isKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_'
+}
+
+func scanOperator(s string) (operator, int) {
+	switch {
+	case strings.HasPrefix(s, "<="):
+		return opLessEqual, 2
+	case strings.HasPrefix(s, ">="):
+		return opGreaterEqual, 2
+	case strings.HasPrefix(s, "<"):
+		return opLess, 1
+	case strings.HasPrefix(s, ">"):
+		return opGreater, 1
+	case strings.HasPrefix(s, ":"), strings.HasPrefix(s, "="):
+		return opEqual, 1
+	default:
+		return "", 0
+	}
+}
+
+func scanValue(query string, i int) (value, int, error) {
+	v := value{column: i + 1}
+
+	if i < len(query) && query[i] == '"' {
+		end := strings.IndexByte(query[i+1:], '"')
+		if end < 0 {
+			return v, 0, &SyntaxError{Column: i + 1, Msg: "unterminated quoted value"}
+		}
+		v.text = query[i+1 : i+1+end]
+		return v, i + end + 2, nil
+	}
+
+	start := i
+	for i < len(query) && query[i] != ' ' && query[i] != ',' && query[i] != '"' {
+		i++
+	}
+	if i == start {
+		return v, 0, &SyntaxError{Column: i + 1, Msg: "expected a value"}
+	}
+	v.text = query[start:i]
+	return v, i, nil
+}
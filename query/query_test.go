@@ -0,0 +1,80 @@
+package query
+
+import (
+	"math"
+	"testing"
+
+	"example.com/pcbook/memsize"
+	"example.com/pcbook/pb"
+	"github.com/test-go/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	backlit := true
+	weightLb := 4.4
+
+	filter, err := Parse(`brand:Dell,Lenovo name:"Thinkpad X1" price<2000 ram>=16GB cpu.cores>=6 panel:OLED ` +
+		`year=2019 screen.resolution>=1920x1080 weight<=4.4lb backlit:true storage>=1TB ssd:true`)
+	require.NoError(t, err)
+
+	expected := &pb.Filter{
+		Brands:              []string{"Dell", "Lenovo"},
+		Names:               []string{"Thinkpad X1"},
+		MaxPriceUsd:         math.Nextafter(2000, 0),
+		MinRam:              &pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE},
+		MinCpuCore:          6,
+		ScreenPanel:         pb.Screen_OLED,
+		MinReleaseYear:      2019,
+		MaxReleaseYear:      2019,
+		MinScreenResolution: &pb.Screen_Resolution{Width: 1920, Height: 1080},
+		MaxWeightKg:         weightLb * memsize.KgPerLb,
+		KeyboardBacklit:     &backlit,
+		MinStorage:          &pb.Memory{Value: 1, Unit: pb.Memory_TERABYTE},
+		SsdOnly:             true,
+	}
+	require.True(t, proto.Equal(expected, filter), "got %v", filter)
+}
+
+func TestParseEmpty(t *testing.T) {
+	t.Parallel()
+
+	filter, err := Parse("  ")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&pb.Filter{}, filter), "got %v", filter)
+}
+
+func TestParseError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		query  string
+		column int
+	}{
+		{query: "color:red", column: 1},
+		{query: "price<2000 ram>=16XB", column: 19},
+		{query: "price 2000", column: 6},
+		{query: "brand:Dell ram<16GB", column: 12},
+		{query: `name:"Thinkpad`, column: 6},
+		{query: "cpu.cores>=six", column: 12},
+		{query: "price<1000,2000", column: 11},
+		{query: "panel:LCD", column: 7},
+		{query: "brand:", column: 7},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.query, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse(tc.query)
+			require.Error(t, err)
+			syntaxErr, ok := err.(*SyntaxError)
+			require.True(t, ok)
+			require.Equal(t, tc.column, syntaxErr.Column, syntaxErr.Error())
+		})
+	}
+}
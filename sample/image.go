@@ -0,0 +1,22 @@
+package sample
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math/rand"
+)
+
+// NewPNG returns a PNG of random pixels. Noise doesn't compress, so the size grows with the dimensions.
+func NewPNG(width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	rand.Read(img.Pix)
+
+	data := bytes.Buffer{}
+	err := png.Encode(&data, img)
+	if err != nil {
+		// encoding to memory cannot fail
+		panic(err)
+	}
+	return data.Bytes()
+}
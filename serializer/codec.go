@@ -0,0 +1,171 @@
+package serializer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes a single message, such as the content of a file
+type Codec interface {
+	Marshal(message proto.Message) ([]byte, error)
+	Unmarshal(data []byte, message proto.Message) error
+}
+
+// StreamCodec writes and reads a sequence of messages
+type StreamCodec interface {
+	// Write appends message to writer
+	Write(message proto.Message, writer io.Writer) error
+	// Read reads the next message and returns the number of bytes it consumed, or io.EOF at the end of the stream
+	Read(message proto.Message, reader *bufio.Reader) (int, error)
+}
+
+type BinaryCodec struct {
+	MarshalOptions   proto.MarshalOptions
+	UnmarshalOptions proto.UnmarshalOptions
+}
+
+func (codec BinaryCodec) Marshal(message proto.Message) ([]byte, error) {
+	return codec.MarshalOptions.Marshal(message)
+}
+
+func (codec BinaryCodec) Unmarshal(data []byte, message proto.Message) error {
+	return codec.UnmarshalOptions.Unmarshal(data, message)
+}
+
+// JSONCodec is strict about unknown fields unless UnmarshalOptions.DiscardUnknown is set
+type JSONCodec struct {
+	MarshalOptions   protojson.MarshalOptions
+	UnmarshalOptions protojson.UnmarshalOptions
+}
+
+func (codec JSONCodec) Marshal(message proto.Message) ([]byte, error) {
+	return codec.MarshalOptions.Marshal(message)
+}
+
+func (codec JSONCodec) Unmarshal(data []byte, message proto.Message) error {
+	return codec.UnmarshalOptions.Unmarshal(data, message)
+}
+
+// TextCodec is strict about unknown fields unless UnmarshalOptions.DiscardUnknown is set
+type TextCodec struct {
+	MarshalOptions   prototext.MarshalOptions
+	UnmarshalOptions prototext.UnmarshalOptions
+}
+
+func (codec TextCodec) Marshal(message proto.Message) ([]byte, error) {
+	return codec.MarshalOptions.Marshal(message)
+}
+
+func (codec TextCodec) Unmarshal(data []byte, message proto.Message) error {
+	return codec.UnmarshalOptions.Unmarshal(data, message)
+}
+
+// DelimitedCodec streams binary messages, each prefixed with its size as a varint
+type DelimitedCodec struct{}
+
+func (DelimitedCodec) Write(message proto.Message, writer io.Writer) error {
+	return WriteProtobufToBinaryStream(message, writer)
+}
+
+func (DelimitedCodec) Read(message proto.Message, reader *bufio.Reader) (int, error) {
+	return ReadProtobufFromBinaryStream(message, reader)
+}
+
+type registry[T any] struct {
+	mutex      sync.RWMutex
+	kind       string
+	byName     map[string]T
+	extensions map[string]string
+}
+
+func newRegistry[T any](kind string) *registry[T] {
+	return &registry[T]{
+		kind:       kind,
+		byName:     make(map[string]T),
+		extensions: make(map[string]string),
+	}
+}
+
+func (r *registry[T]) register(name string, codec T, extensions ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.byName[name] = codec
+	for _, extension := range extensions {
+		r.extensions[strings.ToLower(extension)] = name
+	}
+}
+
+func (r *registry[T]) byNameOrFile(name string, filename string) (T, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var none T
+	if name == "" {
+		extension := strings.ToLower(filepath.Ext(filename))
+		name = r.extensions[extension]
+		if name == "" {
+			return none, fmt.Errorf("no %s is registered for extension %q", r.kind, extension)
+		}
+	}
+
+	codec, ok := r.byName[name]
+	if !ok {
+		names := make([]string, 0, len(r.byName))
+		for name := range r.byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return none, fmt.Errorf("unknown %s %q, use one of %s", r.kind, name, strings.Join(names, ", "))
+	}
+	return codec, nil
+}
+
+var (
+	codecs       = newRegistry[Codec]("codec")
+	streamCodecs = newRegistry[StreamCodec]("stream codec")
+)
+
+func init() {
+	RegisterCodec("binary", BinaryCodec{}, ".bin", ".pb")
+	RegisterCodec("json", JSONCodec{MarshalOptions: DefaultJSONMarshalOptions()}, ".json")
+	RegisterCodec("json-lenient", JSONCodec{
+		MarshalOptions:   DefaultJSONMarshalOptions(),
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	})
+	RegisterCodec("text", TextCodec{MarshalOptions: prototext.MarshalOptions{Multiline: true}}, ".txt", ".textpb")
+
+	RegisterStreamCodec("delimited", DelimitedCodec{}, ".bin", ".binpb")
+	RegisterStreamCodec("jsonl", JSONLinesCodec{MarshalOptions: protojson.MarshalOptions{UseProtoNames: true}}, ".jsonl", ".ndjson")
+}
+
+// RegisterCodec makes codec available under name and for files with the given extensions,
+// replacing any codec previously registered for them
+func RegisterCodec(name string, codec Codec, extensions ...string) {
+	codecs.register(name, codec, extensions...)
+}
+
+// LookupCodec returns the codec registered as name, or for the extension of filename if name is empty
+func LookupCodec(name string, filename string) (Codec, error) {
+	return codecs.byNameOrFile(name, filename)
+}
+
+// RegisterStreamCodec makes codec available under name and for files with the given extensions,
+// replacing any codec previously registered for them
+func RegisterStreamCodec(name string, codec StreamCodec, extensions ...string) {
+	streamCodecs.register(name, codec, extensions...)
+}
+
+// LookupStreamCodec returns the stream codec registered as name, or for the extension of filename if name is empty
+func LookupStreamCodec(name string, filename string) (StreamCodec, error) {
+	return streamCodecs.byNameOrFile(name, filename)
+}
@@ -0,0 +1,611 @@
+package serializer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/pcbook/memsize"
+	"example.com/pcbook/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Laptops are written to CSV with one column per field. Nested fields are flattened, e.g. cpu.number_cores.
+// Memory sizes are written like "16 GB", resolutions like "1920x1080" and timestamps in RFC 3339.
+// The weight goes to either weight_kg or weight_lb, depending on the unit it was given in.
+//
+// Repeated fields are written in a single column, with items separated by ";" and the fields of an item by "|":
+//
+//	gpus:     brand|name|min_ghz|max_ghz|memory, e.g. "NVIDIA|RTX 2070|1.2|1.8|8 GB; AMD|RX 580|1|1.4|4 GB"
+//	storages: driver|memory, e.g. "SSD|512 GB; HDD|1 TB"
+const (
+	csvItemSeparator  = ";"
+	csvFieldSeparator = "|"
+)
+
+// CSVError reports a value that cannot be parsed, at a 1-based row and column of the file.
+// The header is row 1.
+type CSVError struct {
+	Row    int
+	Column int
+	Header string
+	Err    error
+}
+
+func (err *CSVError) Error() string {
+	return fmt.Sprintf("row %d, column %d (%s): %v", err.Row, err.Column, err.Header, err.Err)
+}
+
+func (err *CSVError) Unwrap() error {
+	return err.Err
+}
+
+const (
+	UnknownColumnsError  = "error"
+	UnknownColumnsIgnore = "ignore"
+)
+
+// CSVMapping tells how the headers of a CSV file map to laptop columns
+type CSVMapping struct {
+	// Columns maps a header to a column name, e.g. "Model": "name". An empty column name ignores the header.
+	Columns map[string]string `json:"columns"`
+	// UnknownColumns tells what to do with headers that are neither mapped nor column names: "error" or "ignore".
+	// The default is "error".
+	UnknownColumns string `json:"unknown_columns"`
+}
+
+// ReadCSVMappingFile reads a mapping from a JSON file such as:
+//
+//	{"columns": {"Model": "name", "Price": "price_usd", "Notes": ""}, "unknown_columns": "ignore"}
+func ReadCSVMappingFile(filename string) (*CSVMapping, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read mapping file: %w", err)
+	}
+
+	mapping := &CSVMapping{}
+	err = json.Unmarshal(data, mapping)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse mapping file: %w", err)
+	}
+
+	for header, name := range mapping.Columns {
+		if name != "" && findCSVColumn(name) == nil {
+			return nil, fmt.Errorf("header %q is mapped to unknown column %q", header, name)
+		}
+	}
+	switch mapping.UnknownColumns {
+	case "", UnknownColumnsError, UnknownColumnsIgnore:
+	default:
+		return nil, fmt.Errorf("unknown_columns must be %q or %q", UnknownColumnsError, UnknownColumnsIgnore)
+	}
+	return mapping, nil
+}
+
+// header returns the header under which column is written
+func (mapping *CSVMapping) header(column string) string {
+	if mapping != nil {
+		for header, name := range mapping.Columns {
+			if name == column {
+				return header
+			}
+		}
+	}
+	return column
+}
+
+// column returns the column of a header, nil if the header is ignored
+func (mapping *CSVMapping) column(header string) (*csvColumn, error) {
+	if mapping != nil {
+		if name, ok := mapping.Columns[header]; ok {
+			if name == "" {
+				return nil, nil
+			}
+			return findCSVColumn(name), nil
+		}
+	}
+
+	column := findCSVColumn(header)
+	if column == nil {
+		if mapping != nil && mapping.UnknownColumns == UnknownColumnsIgnore {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unknown column")
+	}
+	return column, nil
+}
+
+// CSVWriter writes laptops as CSV rows, after a header row
+type CSVWriter struct {
+	writer        *csv.Writer
+	mapping       *CSVMapping
+	headerWritten bool
+}
+
+// NewCSVWriter returns a writer that names the columns according to mapping, which may be nil
+func NewCSVWriter(writer io.Writer, mapping *CSVMapping) *CSVWriter {
+	return &CSVWriter{
+		writer:  csv.NewWriter(writer),
+		mapping: mapping,
+	}
+}
+
+func (w *CSVWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+
+	record := make([]string, len(csvColumns))
+	for i, column := range csvColumns {
+		record[i] = w.mapping.header(column.name)
+	}
+	return w.writer.Write(record)
+}
+
+func (w *CSVWriter) Write(laptop *pb.Laptop) error {
+	err := w.writeHeader()
+	if err != nil {
+		return fmt.Errorf("cannot write csv header: %w", err)
+	}
+
+	record := make([]string, len(csvColumns))
+	for i, column := range csvColumns {
+		record[i] = column.format(laptop)
+	}
+	err = w.writer.Write(record)
+	if err != nil {
+		return fmt.Errorf("cannot write csv row: %w", err)
+	}
+	return nil
+}
+
+// Flush writes any buffered data, and the header if no laptop was written
+func (w *CSVWriter) Flush() error {
+	err := w.writeHeader()
+	if err != nil {
+		return fmt.Errorf("cannot write csv header: %w", err)
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// CSVReader reads laptops from CSV rows, after a header row
+type CSVReader struct {
+	reader  *csv.Reader
+	headers []string
+	columns []*csvColumn
+	row     int
+}
+
+// NewCSVReader reads the header row and maps it to columns according to mapping, which may be nil
+func NewCSVReader(reader io.Reader, mapping *CSVMapping) (*CSVReader, error) {
+	r := &CSVReader{
+		reader: csv.NewReader(reader),
+		row:    1,
+	}
+	r.reader.TrimLeadingSpace = true
+
+	headers, err := r.reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read csv header: %w", err)
+	}
+	r.headers = headers
+
+	seen := make(map[string]bool)
+	for i, header := range headers {
+		column, err := mapping.column(strings.TrimSpace(header))
+		if err != nil {
+			return nil, &CSVError{Row: 1, Column: i + 1, Header: header, Err: err}
+		}
+		if column != nil {
+			if seen[column.name] {
+				return nil, &CSVError{Row: 1, Column: i + 1, Header: header, Err: fmt.Errorf("column %s appears twice", column.name)}
+			}
+			seen[column.name] = true
+		}
+		r.columns = append(r.columns, column)
+	}
+	return r, nil
+}
+
+// Read returns the laptop of the next row, or io.EOF when there are no more rows
+func (r *CSVReader) Read() (*pb.Laptop, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	r.row++
+	if err != nil {
+		return nil, fmt.Errorf("cannot read csv row %d: %w", r.row, err)
+	}
+
+	laptop := &pb.Laptop{}
+	for i, value := range record {
+		column := r.columns[i]
+		value = strings.TrimSpace(value)
+		if column == nil || value == "" {
+			continue
+		}
+		err := column.parse(laptop, value)
+		if err != nil {
+			return nil, &CSVError{Row: r.row, Column: i + 1, Header: r.headers[i], Err: err}
+		}
+	}
+	return laptop, nil
+}
+
+type csvColumn struct {
+	name   string
+	format func(laptop *pb.Laptop) string
+	// parse is only called with non-empty values
+	parse func(laptop *pb.Laptop, value string) error
+}
+
+func findCSVColumn(name string) *csvColumn {
+	for i := range csvColumns {
+		if strings.EqualFold(csvColumns[i].name, name) {
+			return &csvColumns[i]
+		}
+	}
+	return nil
+}
+
+func cpuOf(laptop *pb.Laptop) *pb.CPU {
+	if laptop.Cpu == nil {
+		laptop.Cpu = &pb.CPU{}
+	}
+	return laptop.Cpu
+}
+
+func screenOf(laptop *pb.Laptop) *pb.Screen {
+	if laptop.Screen == nil {
+		laptop.Screen = &pb.Screen{}
+	}
+	return laptop.Screen
+}
+
+func keyboardOf(laptop *pb.Laptop) *pb.Keyboard {
+	if laptop.Keyboard == nil {
+		laptop.Keyboard = &pb.Keyboard{}
+	}
+	return laptop.Keyboard
+}
+
+var csvColumns = []csvColumn{
+	{
+		name:   "id",
+		format: func(laptop *pb.Laptop) string { return laptop.GetId() },
+		parse:  func(laptop *pb.Laptop, value string) error { laptop.Id = value; return nil },
+	},
+	{
+		name:   "brand",
+		format: func(laptop *pb.Laptop) string { return laptop.GetBrand() },
+		parse:  func(laptop *pb.Laptop, value string) error { laptop.Brand = value; return nil },
+	},
+	{
+		name:   "name",
+		format: func(laptop *pb.Laptop) string { return laptop.GetName() },
+		parse:  func(laptop *pb.Laptop, value string) error { laptop.Name = value; return nil },
+	},
+	{
+		name:   "cpu.brand",
+		format: func(laptop *pb.Laptop) string { return laptop.GetCpu().GetBrand() },
+		parse:  func(laptop *pb.Laptop, value string) error { cpuOf(laptop).Brand = value; return nil },
+	},
+	{
+		name:   "cpu.name",
+		format: func(laptop *pb.Laptop) string { return laptop.GetCpu().GetName() },
+		parse:  func(laptop *pb.Laptop, value string) error { cpuOf(laptop).Name = value; return nil },
+	},
+	{
+		name:   "cpu.number_cores",
+		format: func(laptop *pb.Laptop) string { return formatUint(laptop.GetCpu().GetNumberCores()) },
+		parse: func(laptop *pb.Laptop, value string) error {
+			return parseUint(value, &cpuOf(laptop).NumberCores)
+		},
+	},
+	{
+		name:   "cpu.number_threads",
+		format: func(laptop *pb.Laptop) string { return formatUint(laptop.GetCpu().GetNumberThreads()) },
+		parse: func(laptop *pb.Laptop, value string) error {
+			return parseUint(value, &cpuOf(laptop).NumberThreads)
+		},
+	},
+	{
+		name:   "cpu.min_ghz",
+		format: func(laptop *pb.Laptop) string { return formatFloat(laptop.GetCpu().GetMinGhz()) },
+		parse:  func(laptop *pb.Laptop, value string) error { return parseFloat(value, &cpuOf(laptop).MinGhz) },
+	},
+	{
+		name:   "cpu.max_ghz",
+		format: func(laptop *pb.Laptop) string { return formatFloat(laptop.GetCpu().GetMaxGhz()) },
+		parse:  func(laptop *pb.Laptop, value string) error { return parseFloat(value, &cpuOf(laptop).MaxGhz) },
+	},
+	{
+		name:   "ram",
+		format: func(laptop *pb.Laptop) string { return memsize.Format(laptop.GetRam()) },
+		parse: func(laptop *pb.Laptop, value string) error {
+			memory, err := memsize.Parse(value)
+			laptop.Ram = memory
+			return err
+		},
+	},
+	{
+		name:   "gpus",
+		format: formatGPUs,
+		parse:  parseGPUs,
+	},
+	{
+		name:   "storages",
+		format: formatStorages,
+		parse:  parseStorages,
+	},
+	{
+		name: "screen.size_inch",
+		format: func(laptop *pb.Laptop) string {
+			return strconv.FormatFloat(float64(laptop.GetScreen().GetSizeInch()), 'f', -1, 32)
+		},
+		parse: func(laptop *pb.Laptop, value string) error {
+			x, err := strconv.ParseFloat(value, 32)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", value)
+			}
+			screenOf(laptop).SizeInch = float32(x)
+			return nil
+		},
+	},
+	{
+		name: "screen.resolution",
+		format: func(laptop *pb.Laptop) string {
+			resolution := laptop.GetScreen().GetResolution()
+			if resolution == nil {
+				return ""
+			}
+			return fmt.Sprintf("%dx%d", resolution.GetWidth(), resolution.GetHeight())
+		},
+		parse: func(laptop *pb.Laptop, value string) error {
+			width, height, ok := strings.Cut(strings.ToLower(value), "x")
+			w, err1 := strconv.ParseUint(strings.TrimSpace(width), 10, 32)
+			h, err2 := strconv.ParseUint(strings.TrimSpace(height), 10, 32)
+			if !ok || err1 != nil || err2 != nil {
+				return fmt.Errorf("invalid resolution %q, expected WIDTHxHEIGHT", value)
+			}
+			screenOf(laptop).Resolution = &pb.Screen_Resolution{Width: uint32(w), Height: uint32(h)}
+			return nil
+		},
+	},
+	{
+		name:   "screen.panel",
+		format: func(laptop *pb.Laptop) string { return laptop.GetScreen().GetPanel().String() },
+		parse: func(laptop *pb.Laptop, value string) error {
+			n, err := parseEnum(value, pb.Screen_Panel_value)
+			screenOf(laptop).Panel = pb.Screen_Panel(n)
+			return err
+		},
+	},
+	{
+		name:   "screen.multitouch",
+		format: func(laptop *pb.Laptop) string { return strconv.FormatBool(laptop.GetScreen().GetMultitouch()) },
+		parse:  func(laptop *pb.Laptop, value string) error { return parseBool(value, &screenOf(laptop).Multitouch) },
+	},
+	{
+		name:   "keyboard.layout",
+		format: func(laptop *pb.Laptop) string { return laptop.GetKeyboard().GetLayout().String() },
+		parse: func(laptop *pb.Laptop, value string) error {
+			n, err := parseEnum(value, pb.Keyboard_Layout_value)
+			keyboardOf(laptop).Layout = pb.Keyboard_Layout(n)
+			return err
+		},
+	},
+	{
+		name:   "keyboard.backlit",
+		format: func(laptop *pb.Laptop) string { return strconv.FormatBool(laptop.GetKeyboard().GetBacklit()) },
+		parse:  func(laptop *pb.Laptop, value string) error { return parseBool(value, &keyboardOf(laptop).Backlit) },
+	},
+	{
+		name: "weight_kg",
+		format: func(laptop *pb.Laptop) string {
+			if weight, ok := laptop.GetWeight().(*pb.Laptop_WeightKg); ok {
+				return formatFloat(weight.WeightKg)
+			}
+			return ""
+		},
+		parse: func(laptop *pb.Laptop, value string) error {
+			weight := &pb.Laptop_WeightKg{}
+			err := parseWeight(laptop, value, &weight.WeightKg)
+			laptop.Weight = weight
+			return err
+		},
+	},
+	{
+		name: "weight_lb",
+		format: func(laptop *pb.Laptop) string {
+			if weight, ok := laptop.GetWeight().(*pb.Laptop_WeightLb); ok {
+				return formatFloat(weight.WeightLb)
+			}
+			return ""
+		},
+		parse: func(laptop *pb.Laptop, value string) error {
+			weight := &pb.Laptop_WeightLb{}
+			err := parseWeight(laptop, value, &weight.WeightLb)
+			laptop.Weight = weight
+			return err
+		},
+	},
+	{
+		name:   "price_usd",
+		format: func(laptop *pb.Laptop) string { return formatFloat(laptop.GetPriceUsd()) },
+		parse:  func(laptop *pb.Laptop, value string) error { return parseFloat(value, &laptop.PriceUsd) },
+	},
+	{
+		name:   "release_year",
+		format: func(laptop *pb.Laptop) string { return formatUint(laptop.GetReleaseYear()) },
+		parse:  func(laptop *pb.Laptop, value string) error { return parseUint(value, &laptop.ReleaseYear) },
+	},
+	{
+		name: "updated_at",
+		format: func(laptop *pb.Laptop) string {
+			if laptop.GetUpdatedAt() == nil {
+				return ""
+			}
+			return laptop.GetUpdatedAt().AsTime().Format(time.RFC3339Nano)
+		},
+		parse: func(laptop *pb.Laptop, value string) error {
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return fmt.Errorf("invalid time %q, expected RFC 3339", value)
+			}
+			laptop.UpdatedAt = timestamppb.New(t)
+			return nil
+		},
+	},
+}
+
+func formatUint(x uint32) string {
+	return strconv.FormatUint(uint64(x), 10)
+}
+
+func parseUint(value string, x *uint32) error {
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid whole number %q", value)
+	}
+	*x = uint32(n)
+	return nil
+}
+
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+func parseFloat(value string, x *float64) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*x = f
+	return nil
+}
+
+func parseBool(value string, b *bool) error {
+	v, err := strconv.ParseBool(strings.ToLower(value))
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q, expected true or false", value)
+	}
+	*b = v
+	return nil
+}
+
+func parseEnum(value string, values map[string]int32) (int32, error) {
+	n, ok := values[strings.ToUpper(value)]
+	if !ok {
+		return 0, fmt.Errorf("unknown value %q", value)
+	}
+	return n, nil
+}
+
+func parseWeight(laptop *pb.Laptop, value string, weight *float64) error {
+	if laptop.GetWeight() != nil {
+		return fmt.Errorf("weight_kg and weight_lb cannot both be set")
+	}
+	return parseFloat(value, weight)
+}
+
+// splitItems splits a repeated field into items, each made of n fields
+func splitItems(value string, n int) ([][]string, error) {
+	var items [][]string
+	for _, item := range strings.Split(value, csvItemSeparator) {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		fields := strings.Split(item, csvFieldSeparator)
+		if len(fields) != n {
+			return nil, fmt.Errorf("item %q has %d fields instead of %d", strings.TrimSpace(item), len(fields), n)
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		items = append(items, fields)
+	}
+	return items, nil
+}
+
+func formatGPUs(laptop *pb.Laptop) string {
+	items := make([]string, 0, len(laptop.GetGpus()))
+	for _, gpu := range laptop.GetGpus() {
+		items = append(items, strings.Join([]string{
+			gpu.GetBrand(),
+			gpu.GetName(),
+			formatFloat(gpu.GetMinGhz()),
+			formatFloat(gpu.GetMaxGhz()),
+			memsize.Format(gpu.GetMemory()),
+		}, csvFieldSeparator))
+	}
+	return strings.Join(items, csvItemSeparator+" ")
+}
+
+func parseGPUs(laptop *pb.Laptop, value string) error {
+	items, err := splitItems(value, 5)
+	if err != nil {
+		return err
+	}
+	for _, fields := range items {
+		gpu := &pb.GPU{Brand: fields[0], Name: fields[1]}
+		err := parseFloat(fields[2], &gpu.MinGhz)
+		if err != nil {
+			return err
+		}
+		err = parseFloat(fields[3], &gpu.MaxGhz)
+		if err != nil {
+			return err
+		}
+		gpu.Memory, err = memsize.Parse(fields[4])
+		if err != nil {
+			return err
+		}
+		laptop.Gpus = append(laptop.Gpus, gpu)
+	}
+	return nil
+}
+
+func formatStorages(laptop *pb.Laptop) string {
+	items := make([]string, 0, len(laptop.GetStorages()))
+	for _, storage := range laptop.GetStorages() {
+		driver := storage.GetDriver().String()
+		if storage.GetDriver() == pb.Storage_SDD {
+			driver = "SSD"
+		}
+		items = append(items, driver+csvFieldSeparator+memsize.Format(storage.GetMemory()))
+	}
+	return strings.Join(items, csvItemSeparator+" ")
+}
+
+func parseStorages(laptop *pb.Laptop, value string) error {
+	items, err := splitItems(value, 2)
+	if err != nil {
+		return err
+	}
+	for _, fields := range items {
+		driver := strings.ToUpper(fields[0])
+		// the enum is spelled SDD, but people write SSD
+		if driver == "SSD" {
+			driver = pb.Storage_SDD.String()
+		}
+		n, err := parseEnum(driver, pb.Storage_Driver_value)
+		if err != nil {
+			return err
+		}
+		memory, err := memsize.Parse(fields[1])
+		if err != nil {
+			return err
+		}
+		laptop.Storages = append(laptop.Storages, &pb.Storage{Driver: pb.Storage_Driver(n), Memory: memory})
+	}
+	return nil
+}
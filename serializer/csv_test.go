@@ -0,0 +1,157 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	laptops := []*pb.Laptop{sample.NewLaptop(), sample.NewLaptop(), sample.NewLaptop()}
+	laptops[1].Weight = &pb.Laptop_WeightLb{WeightLb: 4.2}
+
+	var buffer bytes.Buffer
+	writer := NewCSVWriter(&buffer, nil)
+	for _, laptop := range laptops {
+		require.NoError(t, writer.Write(laptop))
+	}
+	require.NoError(t, writer.Flush())
+
+	reader, err := NewCSVReader(&buffer, nil)
+	require.NoError(t, err)
+	for _, laptop := range laptops {
+		other, err := reader.Read()
+		require.NoError(t, err)
+		require.True(t, proto.Equal(laptop, other), "%v != %v", laptop, other)
+	}
+	_, err = reader.Read()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestCSVReader(t *testing.T) {
+	t.Parallel()
+
+	input := `Model,brand,ram,gpus,storages,screen.resolution,weight_lb,Notes
+XPS 13,Dell,16 GB,NVIDIA|RTX 2070|1.2|1.8|8 GB; AMD|RX 580|1|1.4|4gb,SSD|512 GB;HDD|1 TB,1920x1080,2.8,nice
+`
+	mapping := &CSVMapping{Columns: map[string]string{"Model": "name", "Notes": ""}}
+	reader, err := NewCSVReader(strings.NewReader(input), mapping)
+	require.NoError(t, err)
+
+	laptop, err := reader.Read()
+	require.NoError(t, err)
+	expected := &pb.Laptop{
+		Brand: "Dell",
+		Name:  "XPS 13",
+		Ram:   &pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE},
+		Gpus: []*pb.GPU{
+			{Brand: "NVIDIA", Name: "RTX 2070", MinGhz: 1.2, MaxGhz: 1.8, Memory: &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE}},
+			{Brand: "AMD", Name: "RX 580", MinGhz: 1, MaxGhz: 1.4, Memory: &pb.Memory{Value: 4, Unit: pb.Memory_GIGABYTE}},
+		},
+		Storages: []*pb.Storage{
+			{Driver: pb.Storage_SDD, Memory: &pb.Memory{Value: 512, Unit: pb.Memory_GIGABYTE}},
+			{Driver: pb.Storage_HDD, Memory: &pb.Memory{Value: 1, Unit: pb.Memory_TERABYTE}},
+		},
+		Screen: &pb.Screen{Resolution: &pb.Screen_Resolution{Width: 1920, Height: 1080}},
+		Weight: &pb.Laptop_WeightLb{WeightLb: 2.8},
+	}
+	require.True(t, proto.Equal(expected, laptop), "%v", laptop)
+}
+
+func TestCSVReaderErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		input   string
+		mapping *CSVMapping
+		row     int
+		column  int
+	}{
+		{
+			name:   "unknown_column",
+			input:  "brand,color\nDell,red\n",
+			row:    1,
+			column: 2,
+		},
+		{
+			name:   "duplicate_column",
+			input:  "brand,Brand\nDell,Dell\n",
+			row:    1,
+			column: 2,
+		},
+		{
+			name:   "invalid_memory",
+			input:  "brand,ram\nDell,16\nDell,lots\n",
+			row:    2,
+			column: 2,
+		},
+		{
+			name:   "both_weights",
+			input:  "weight_kg,weight_lb\n1.2,\n1.2,2.6\n",
+			row:    3,
+			column: 2,
+		},
+		{
+			name:    "invalid_gpu_after_ignored_column",
+			input:   "color,gpus\nred,NVIDIA|RTX 2070\n",
+			mapping: &CSVMapping{UnknownColumns: UnknownColumnsIgnore},
+			row:     2,
+			column:  2,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader, err := NewCSVReader(strings.NewReader(tc.input), tc.mapping)
+			for err == nil {
+				_, err = reader.Read()
+			}
+
+			var csvErr *CSVError
+			require.True(t, errors.As(err, &csvErr), "unexpected error: %v", err)
+			require.Equal(t, tc.row, csvErr.Row)
+			require.Equal(t, tc.column, csvErr.Column)
+		})
+	}
+}
+
+func TestCSVWriterMapping(t *testing.T) {
+	t.Parallel()
+
+	mappingFile := filepath.Join(t.TempDir(), "mapping.json")
+	err := os.WriteFile(mappingFile, []byte(`{"columns": {"Model": "name", "Price": "price_usd"}}`), 0644)
+	require.NoError(t, err)
+	mapping, err := ReadCSVMappingFile(mappingFile)
+	require.NoError(t, err)
+
+	var buffer bytes.Buffer
+	writer := NewCSVWriter(&buffer, mapping)
+	require.NoError(t, writer.Write(&pb.Laptop{Name: "XPS 13", PriceUsd: 1500.5}))
+	require.NoError(t, writer.Flush())
+
+	lines := strings.Split(buffer.String(), "\n")
+	require.Contains(t, lines[0], "brand,Model,")
+	require.Contains(t, lines[0], ",Price,")
+	require.Contains(t, lines[1], ",XPS 13,")
+	require.Contains(t, lines[1], ",1500.5,")
+
+	err = os.WriteFile(mappingFile, []byte(`{"columns": {"Model": "model"}}`), 0644)
+	require.NoError(t, err)
+	_, err = ReadCSVMappingFile(mappingFile)
+	require.Error(t, err)
+}
@@ -4,9 +4,54 @@ import (
 	"fmt"
 	"os"
 
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
+// WriteProtobufToFile writes message to filename with codec, or with the codec registered for its extension if codec is nil
+func WriteProtobufToFile(message proto.Message, filename string, codec Codec) error {
+	if codec == nil {
+		var err error
+		codec, err = LookupCodec("", filename)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := codec.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("cannot marshal proto message: %w", err)
+	}
+
+	err = os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot write data to file: %w", err)
+	}
+	return nil
+}
+
+// ReadProtobufFromFile reads filename into message with codec, or with the codec registered for its extension if codec is nil
+func ReadProtobufFromFile(message proto.Message, filename string, codec Codec) error {
+	if codec == nil {
+		var err error
+		codec, err = LookupCodec("", filename)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("cannot read data from file: %w", err)
+	}
+
+	err = codec.Unmarshal(data, message)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal data to proto message: %w", err)
+	}
+	return nil
+}
+
 func WriteProtobufToJSONFile(message proto.Message, filename string) error {
 	data, err := ProtobuffToJSON(message)
 
@@ -22,7 +67,25 @@ func WriteProtobufToJSONFile(message proto.Message, filename string) error {
 
 }
 
+// ReadProtobufFromJSONFile fails on fields that message doesn't have
 func ReadProtobufFromJSONFile(message proto.Message, filename string) error {
+	return readProtobufFromJSONFile(message, filename, protojson.UnmarshalOptions{})
+}
+
+// ReadProtobufFromJSONFileLenient ignores fields that message doesn't have, such as those written by a newer version
+func ReadProtobufFromJSONFileLenient(message proto.Message, filename string) error {
+	return readProtobufFromJSONFile(message, filename, protojson.UnmarshalOptions{DiscardUnknown: true})
+}
+
+func readProtobufFromJSONFile(message proto.Message, filename string, options protojson.UnmarshalOptions) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("cannot read json data from file: %w", err)
+	}
+	err = options.Unmarshal(data, message)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal json to proto message: %w", err)
+	}
 	return nil
 }
 
@@ -1,11 +1,17 @@
 package serializer
 
 import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"example.com/pcbook/pb"
 	"example.com/pcbook/sample"
 	"github.com/test-go/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -27,3 +33,133 @@ func TestFileSerializer(t *testing.T) {
 	err = WriteProtobufToJSONFile(laptop1, jsonFile)
 	require.NoError(t, err)
 }
+
+func TestJSONFileSerializer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	laptop1 := sample.NewLaptop()
+
+	jsonFile := filepath.Join(dir, "laptop.json")
+	err := WriteProtobufToJSONFile(laptop1, jsonFile)
+	require.NoError(t, err)
+
+	laptop2 := &pb.Laptop{}
+	err = ReadProtobufFromJSONFile(laptop2, jsonFile)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(laptop1, laptop2))
+
+	// a file written by a newer version may have fields this one doesn't know
+	data, err := os.ReadFile(jsonFile)
+	require.NoError(t, err)
+	newerFile := filepath.Join(dir, "newer.json")
+	err = os.WriteFile(newerFile, append([]byte(`{"new_field": 1,`), data[1:]...), 0644)
+	require.NoError(t, err)
+
+	err = ReadProtobufFromJSONFile(&pb.Laptop{}, newerFile)
+	require.Error(t, err)
+
+	laptop3 := &pb.Laptop{}
+	err = ReadProtobufFromJSONFileLenient(laptop3, newerFile)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(laptop1, laptop3))
+}
+
+func TestCodecs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		filename string
+	}{
+		{name: "binary", filename: "laptop.bin"},
+		{name: "json", filename: "laptop.json"},
+		{name: "json-lenient", filename: "laptop.json"},
+		{name: "text", filename: "laptop.txt"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, name := range []string{tc.name, ""} {
+				codec, err := LookupCodec(name, tc.filename)
+				require.NoError(t, err)
+
+				filename := filepath.Join(t.TempDir(), tc.filename)
+				laptop1 := sample.NewLaptop()
+				err = WriteProtobufToFile(laptop1, filename, codec)
+				require.NoError(t, err)
+
+				laptop2 := &pb.Laptop{}
+				err = ReadProtobufFromFile(laptop2, filename, nil)
+				require.NoError(t, err)
+				require.True(t, proto.Equal(laptop1, laptop2))
+			}
+		})
+	}
+
+	_, err := LookupCodec("yaml", "")
+	require.Error(t, err)
+	_, err = LookupCodec("", "laptop.yaml")
+	require.Error(t, err)
+}
+
+func TestStreamCodecs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		filename string
+	}{
+		{name: "delimited", filename: "laptops.bin"},
+		{name: "jsonl", filename: "laptops.jsonl"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			codec, err := LookupStreamCodec(tc.name, "")
+			require.NoError(t, err)
+			other, err := LookupStreamCodec("", tc.filename)
+			require.NoError(t, err)
+			require.Equal(t, codec, other)
+
+			laptops := []*pb.Laptop{sample.NewLaptop(), sample.NewLaptop(), sample.NewLaptop()}
+			var buffer bytes.Buffer
+			for _, laptop := range laptops {
+				err := codec.Write(laptop, &buffer)
+				require.NoError(t, err)
+			}
+
+			reader := bufio.NewReader(&buffer)
+			for _, expected := range laptops {
+				laptop := &pb.Laptop{}
+				_, err := codec.Read(laptop, reader)
+				require.NoError(t, err)
+				require.True(t, proto.Equal(expected, laptop))
+			}
+			_, err = codec.Read(&pb.Laptop{}, reader)
+			require.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestProtobuffToJSONWithOptions(t *testing.T) {
+	t.Parallel()
+
+	laptop := sample.NewLaptop()
+	data, err := ProtobuffToJSONWithOptions(laptop, protojson.MarshalOptions{UseEnumNumbers: true})
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"priceUsd"`)
+	require.NotContains(t, string(data), "\n")
+
+	data, err = ProtobuffToJSON(laptop)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"price_usd"`)
+}
@@ -5,18 +5,20 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func ProtobuffToJSON(message proto.Message) ([]byte, error) {
-	marshaller := protojson.MarshalOptions{
+// DefaultJSONMarshalOptions returns the options used by ProtobuffToJSON and the "json" codec
+func DefaultJSONMarshalOptions() protojson.MarshalOptions {
+	return protojson.MarshalOptions{
 		UseEnumNumbers:  false,
 		EmitUnpopulated: true,
 		Indent:          "   ",
 		UseProtoNames:   true,
 	}
-	// marshaller := jsonpb.Marshaler{
-	// 	EnumsAsInts:  false,
-	// 	EmitDefaults: true,
-	// 	Indent:       "   ",
-	// 	OrigName:     true,
-	// }
-	return marshaller.Marshal(message)
+}
+
+func ProtobuffToJSON(message proto.Message) ([]byte, error) {
+	return ProtobuffToJSONWithOptions(message, DefaultJSONMarshalOptions())
+}
+
+func ProtobuffToJSONWithOptions(message proto.Message, options protojson.MarshalOptions) ([]byte, error) {
+	return options.Marshal(message)
 }
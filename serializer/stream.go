@@ -0,0 +1,141 @@
+package serializer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+const maxStreamMessageSize = 64 << 20
+
+// WriteProtobufToBinaryStream writes the binary encoding of message to writer, prefixed with its size as a varint
+func WriteProtobufToBinaryStream(message proto.Message, writer io.Writer) error {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("cannot marshal proto message to binary: %w", err)
+	}
+
+	record := protowire.AppendVarint(nil, uint64(len(data)))
+	record = append(record, data...)
+
+	_, err = writer.Write(record)
+	if err != nil {
+		return fmt.Errorf("cannot write binary data to stream: %w", err)
+	}
+	return nil
+}
+
+// ReadProtobufFromBinaryStream reads one size-prefixed message written by WriteProtobufToBinaryStream
+// and returns the number of bytes it consumed.
+// It returns io.EOF when the stream ends cleanly and io.ErrUnexpectedEOF when the last message is truncated.
+func ReadProtobufFromBinaryStream(message proto.Message, reader *bufio.Reader) (int, error) {
+	var prefix []byte
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF && len(prefix) == 0 {
+			return 0, io.EOF
+		}
+		if err == io.EOF {
+			return len(prefix), io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return len(prefix), fmt.Errorf("cannot read message size: %w", err)
+		}
+		prefix = append(prefix, b)
+		if b < 0x80 {
+			break
+		}
+		if len(prefix) == binary.MaxVarintLen64 {
+			return len(prefix), fmt.Errorf("invalid message size: varint is too long")
+		}
+	}
+
+	size, n := protowire.ConsumeVarint(prefix)
+	if n < 0 {
+		return len(prefix), fmt.Errorf("invalid message size: %w", protowire.ParseError(n))
+	}
+	if size > maxStreamMessageSize {
+		return len(prefix), fmt.Errorf("message is too large: %d > %d", size, maxStreamMessageSize)
+	}
+
+	data := make([]byte, size)
+	read, err := io.ReadFull(reader, data)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return len(prefix) + read, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return len(prefix) + read, fmt.Errorf("cannot read binary data from stream: %w", err)
+	}
+
+	err = proto.Unmarshal(data, message)
+	if err != nil {
+		return len(prefix) + read, fmt.Errorf("cannot unmarshal binary to proto message: %w", err)
+	}
+	return len(prefix) + read, nil
+}
+
+// WriteProtobufToJSONStream writes message to writer as a single line of JSON, in the JSON Lines format
+func WriteProtobufToJSONStream(message proto.Message, writer io.Writer) error {
+	return JSONLinesCodec{MarshalOptions: protojson.MarshalOptions{UseProtoNames: true}}.Write(message, writer)
+}
+
+// ReadProtobufFromJSONStream reads one line written by WriteProtobufToJSONStream and returns the number of bytes it consumed.
+// Blank lines are skipped. It returns io.EOF when the stream ends.
+func ReadProtobufFromJSONStream(message proto.Message, reader *bufio.Reader) (int, error) {
+	return JSONLinesCodec{}.Read(message, reader)
+}
+
+// JSONLinesCodec streams JSON messages, one per line. MarshalOptions.Multiline and Indent are ignored.
+type JSONLinesCodec struct {
+	MarshalOptions   protojson.MarshalOptions
+	UnmarshalOptions protojson.UnmarshalOptions
+}
+
+func (codec JSONLinesCodec) Write(message proto.Message, writer io.Writer) error {
+	options := codec.MarshalOptions
+	options.Multiline = false
+	options.Indent = ""
+	data, err := options.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("cannot marshal proto message to json: %w", err)
+	}
+
+	_, err = writer.Write(append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("cannot write json data to stream: %w", err)
+	}
+	return nil
+}
+
+func (codec JSONLinesCodec) Read(message proto.Message, reader *bufio.Reader) (int, error) {
+	consumed := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		consumed += len(line)
+		if err != nil && err != io.EOF {
+			return consumed, fmt.Errorf("cannot read json data from stream: %w", err)
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err == io.EOF {
+				return consumed, io.EOF
+			}
+			continue
+		}
+		if consumed > maxStreamMessageSize {
+			return consumed, fmt.Errorf("message is too large: %d > %d", consumed, maxStreamMessageSize)
+		}
+
+		err = codec.UnmarshalOptions.Unmarshal(line, message)
+		if err != nil {
+			return consumed, fmt.Errorf("cannot unmarshal json to proto message: %w", err)
+		}
+		return consumed, nil
+	}
+}
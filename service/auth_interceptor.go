@@ -27,7 +27,7 @@ func (interceptor *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
 		log.Println("--> unary interceptor: ", info.FullMethod)
-		err := interceptor.authorize(ctx, info.FullMethod)
+		ctx, err := interceptor.authorize(ctx, info.FullMethod)
 		if err != nil {
 			return nil, err
 		}
@@ -43,38 +43,71 @@ func (interceptor *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
 		handler grpc.StreamHandler,
 	) error {
 		log.Println("--> stream interceptor: ", info.FullMethod)
-		err := interceptor.authorize(stream.Context(), info.FullMethod)
+		ctx, err := interceptor.authorize(stream.Context(), info.FullMethod)
 		if err != nil {
 			return err
 		}
-		return handler(srv, stream)
+		return handler(srv, &authServerStream{ServerStream: stream, ctx: ctx})
 	}
 }
-func (interceptor *AuthInterceptor) authorize(ctx context.Context, method string) error {
-	accessibleRoles, ok := interceptor.accessibleRoles[method]
-	if !ok {
-		return nil
+
+// authServerStream carries the context with the claims of the user to the handler
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims of the authenticated user, for the methods that require a role
+func ClaimsFromContext(ctx context.Context) (*UserClaim, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*UserClaim)
+	return claims, ok
+}
+
+// authorize returns ctx with the claims of the user when the method requires a role
+func (interceptor *AuthInterceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	if _, ok := interceptor.accessibleRoles[method]; !ok {
+		return ctx, nil
 	}
 
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return status.Errorf(codes.Unauthenticated, "metadata is not provided")
+		return nil, status.Errorf(codes.Unauthenticated, "metadata is not provided")
+	}
+	claims, err := interceptor.checkAccess(method, md["authorization"])
+	if err != nil {
+		return nil, err
 	}
-	values := md["authorization"]
+	return context.WithValue(ctx, claimsKey{}, claims), nil
+}
+
+// checkAccess verifies the access token and returns its claims if their role can call the method,
+// or nil if the method requires no role
+func (interceptor *AuthInterceptor) checkAccess(method string, values []string) (*UserClaim, error) {
+	accessibleRoles, ok := interceptor.accessibleRoles[method]
+	if !ok {
+		return nil, nil
+	}
+
 	if len(values) == 0 {
-		return status.Errorf(codes.Unauthenticated, "authenrization token is not provided")
+		return nil, status.Errorf(codes.Unauthenticated, "authenrization token is not provided")
 	}
 
 	accessToken := values[0]
 	claims, err := interceptor.jwtManager.Verify(accessToken)
 	if err != nil {
-		return status.Errorf(codes.Unauthenticated, "access token is invalid: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "access token is invalid: %v", err)
 	}
 	for _, role := range accessibleRoles {
 		if role == claims.Role {
-			return nil
+			return claims, nil
 		}
 	}
 
-	return status.Errorf(codes.PermissionDenied, "no permission to access this RPC")
+	return nil, status.Errorf(codes.PermissionDenied, "no permission to access this RPC")
 }
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// restRoute is the REST binding of an RPC, as declared by its google.api.http option
+type restRoute struct {
+	httpMethod string
+	segments   []string
+	fullMethod string
+}
+
+// match reports whether the path fits the route, a variable matches exactly one segment.
+// An empty httpMethod matches the routes of every method.
+func (route *restRoute) match(httpMethod string, segments []string) bool {
+	if (httpMethod != "" && route.httpMethod != httpMethod) || len(route.segments) != len(segments) {
+		return false
+	}
+	for i, segment := range route.segments {
+		if !strings.HasPrefix(segment, "{") && segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// HTTPHandler checks the access of the REST calls before mux serves them. The generated gateway calls the servers
+// in-process, so the gRPC interceptors never see them. The routes are read from the google.api.http options
+// of the services in files, so that every REST call is checked against the roles of its RPC.
+func (interceptor *AuthInterceptor) HTTPHandler(mux *runtime.ServeMux, files ...protoreflect.FileDescriptor) http.Handler {
+	routes := restRoutes(files)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+		httpMethod := req.Method
+		if isMethodFallback(req) {
+			// the mux may serve such a request with the handler of any method, through X-HTTP-Method-Override
+			// or its fallback, so it must pass the checks of every route of its path
+			httpMethod = ""
+		}
+		for _, route := range routes {
+			if !route.match(httpMethod, segments) {
+				continue
+			}
+
+			var values []string
+			if authorization := req.Header.Get("Authorization"); authorization != "" {
+				values = append(values, authorization)
+			}
+			claims, err := interceptor.checkAccess(route.fullMethod, values)
+			if err != nil {
+				_, outboundMarshaler := runtime.MarshalerForRequest(mux, req)
+				runtime.HTTPError(ctx, mux, outboundMarshaler, w, req, err)
+				return
+			}
+			if claims != nil {
+				ctx = context.WithValue(ctx, claimsKey{}, claims)
+			}
+		}
+		mux.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// isMethodFallback reports whether the gateway mux may serve req with the handler of another method,
+// which it does for form posts unless it is built with runtime.WithDisablePathLengthFallback
+func isMethodFallback(req *http.Request) bool {
+	return req.Method == http.MethodPost && req.Header.Get("Content-Type") == "application/x-www-form-urlencoded"
+}
+
+func restRoutes(files []protoreflect.FileDescriptor) []*restRoute {
+	var routes []*restRoute
+	for _, file := range files {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := methods.Get(j)
+				rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+				if !ok || rule == nil {
+					continue
+				}
+				fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+				for _, rule := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+					httpMethod, path := httpRulePattern(rule)
+					if path == "" {
+						continue
+					}
+					routes = append(routes, &restRoute{
+						httpMethod: httpMethod,
+						segments:   strings.Split(strings.Trim(path, "/"), "/"),
+						fullMethod: fullMethod,
+					})
+				}
+			}
+		}
+	}
+	return routes
+}
+
+func httpRulePattern(rule *annotations.HttpRule) (string, string) {
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		return pattern.Custom.GetKind(), pattern.Custom.GetPath()
+	default:
+		return "", ""
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/test-go/testify/require"
+)
+
+type restAuthTestCase struct {
+	name   string
+	method string
+	path   string
+	body   string
+	role   string
+	code   int
+	// form posts the body as a form, with override as X-HTTP-Method-Override when it is set
+	form     bool
+	override string
+}
+
+// serveRestAuthTestCases runs the test cases in order against the REST routes of server,
+// with the admin-only methods of the laptop service
+func serveRestAuthTestCases(t *testing.T, server *LaptopServer, testCases []restAuthTestCase) {
+	mux := runtime.NewServeMux()
+	err := pb.RegisterLaptopServiceHandlerServer(context.Background(), mux, server)
+	require.NoError(t, err)
+
+	const laptopServicePath = "/example.pcbook.LaptopService/"
+	jwtManager := NewJWTManager("secret", time.Minute)
+	interceptor := NewAuthInterceptor(jwtManager, map[string][]string{
+		laptopServicePath + "CreateLaptop":    {"admin"},
+		laptopServicePath + "UpdateLaptop":    {"admin"},
+		laptopServicePath + "DeleteLaptop":    {"admin"},
+		laptopServicePath + "DeleteImage":     {"admin"},
+		laptopServicePath + "SetPrimaryImage": {"admin"},
+		laptopServicePath + "ReorderImages":   {"admin"},
+		laptopServicePath + "ImportCatalog":   {"admin"},
+	})
+	handler := interceptor.HTTPHandler(mux, pb.File_proto_laptop_service_proto)
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.form {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if tc.override != "" {
+			req.Header.Set("X-HTTP-Method-Override", tc.override)
+		}
+		switch tc.role {
+		case "":
+		case "invalid":
+			req.Header.Set("Authorization", "not a token")
+		default:
+			token, err := jwtManager.Generate(&User{Username: tc.role + "1", Role: tc.role})
+			require.NoError(t, err)
+			req.Header.Set("Authorization", token)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		require.Equal(t, tc.code, recorder.Code, "%s: %s", tc.name, recorder.Body.String())
+	}
+}
+
+func TestRestAuthLaptops(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+	server := NewLaptopServer(laptopStore, nil, nil)
+
+	path := "/v1/laptop/" + laptop.GetId()
+	serveRestAuthTestCases(t, server, []restAuthTestCase{
+		{name: "get_without_token", method: http.MethodGet, path: path, code: http.StatusOK},
+		{name: "update_without_token", method: http.MethodPut, path: path, body: `{"cpu": {}}`, code: http.StatusUnauthorized},
+		{name: "delete_without_token", method: http.MethodDelete, path: path, code: http.StatusUnauthorized},
+		{name: "delete_invalid_token", method: http.MethodDelete, path: path, role: "invalid", code: http.StatusUnauthorized},
+		{name: "delete_as_user", method: http.MethodDelete, path: path, role: "user", code: http.StatusForbidden},
+		{name: "create_as_user", method: http.MethodPost, path: "/v1/laptop/create", body: `{"laptop": {}}`, role: "user", code: http.StatusForbidden},
+		{name: "override_without_token", method: http.MethodPost, path: path, form: true, override: http.MethodDelete, code: http.StatusUnauthorized},
+		{name: "override_as_user", method: http.MethodPost, path: path, form: true, override: http.MethodDelete, role: "user", code: http.StatusForbidden},
+		{name: "fallback_without_token", method: http.MethodPost, path: path, form: true, code: http.StatusUnauthorized},
+		{name: "import_without_token", method: http.MethodPost, path: "/v1/catalog/import", body: `{}`, code: http.StatusUnauthorized},
+		{name: "import_as_user", method: http.MethodPost, path: "/v1/catalog/import", body: `{}`, role: "user", code: http.StatusForbidden},
+		{name: "delete_as_admin", method: http.MethodDelete, path: path, role: "admin", code: http.StatusOK},
+	})
+
+	found, err := laptopStore.Find(laptop.GetId())
+	require.NoError(t, err)
+	require.Nil(t, found)
+}
+
+func TestRestAuthLaptopImages(t *testing.T) {
+	t.Parallel()
+
+	imageStore, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	laptopID := sample.NewLaptop().GetId()
+	imageID, err := imageStore.Save(laptopID, *bytes.NewBuffer(sample.NewPNG(10, 10)))
+	require.NoError(t, err)
+	server := NewLaptopServer(NewInMemoryLaptopStore(), imageStore, nil)
+
+	imagePath := "/v1/laptop/images/" + imageID
+	galleryPath := "/v1/laptop/" + laptopID + "/images"
+	reorderBody := `{"image_ids": ["` + imageID + `"]}`
+	serveRestAuthTestCases(t, server, []restAuthTestCase{
+		{name: "delete_without_token", method: http.MethodDelete, path: imagePath, code: http.StatusUnauthorized},
+		{name: "delete_as_user", method: http.MethodDelete, path: imagePath, role: "user", code: http.StatusForbidden},
+		{name: "primary_without_token", method: http.MethodPost, path: imagePath + "/primary", body: `{}`, code: http.StatusUnauthorized},
+		{name: "primary_as_user", method: http.MethodPost, path: imagePath + "/primary", body: `{}`, role: "user", code: http.StatusForbidden},
+		{name: "reorder_without_token", method: http.MethodPut, path: galleryPath, body: reorderBody, code: http.StatusUnauthorized},
+		{name: "reorder_as_user", method: http.MethodPut, path: galleryPath, body: reorderBody, role: "user", code: http.StatusForbidden},
+		{name: "reorder_as_admin", method: http.MethodPut, path: galleryPath, body: reorderBody, role: "admin", code: http.StatusOK},
+		{name: "delete_as_admin", method: http.MethodDelete, path: imagePath, role: "admin", code: http.StatusOK},
+	})
+
+	images, err := imageStore.List(laptopID)
+	require.NoError(t, err)
+	require.Empty(t, images)
+}
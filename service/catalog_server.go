@@ -0,0 +1,291 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+
+	"example.com/pcbook/imageformat"
+	"example.com/pcbook/pb"
+	"example.com/pcbook/validate"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportFilter matches every laptop
+var exportFilter = &pb.Filter{}
+
+// ExportCatalog streams every laptop, then every rating, then every image
+func (server *LaptopServer) ExportCatalog(req *pb.ExportCatalogRequest, stream pb.LaptopService_ExportCatalogServer) error {
+	log.Print("receive an export catalog request")
+	ctx := stream.Context()
+
+	count := 0
+	send := func(record *pb.CatalogRecord) error {
+		err := stream.Send(record)
+		if err != nil {
+			return status.Errorf(codes.Unknown, "cannot send record: %v", err)
+		}
+		count++
+		return nil
+	}
+
+	err := server.laptopStore.Search(ctx, exportFilter, "", func(laptop *pb.Laptop) error {
+		return send(&pb.CatalogRecord{Record: &pb.CatalogRecord_Laptop{Laptop: laptop}})
+	})
+	if err != nil {
+		return exportErr(ctx, "cannot export laptops", err)
+	}
+
+	if server.ratingStore != nil {
+		err = server.ratingStore.Range(ctx, func(laptopID string, rating *Rating) error {
+			return send(&pb.CatalogRecord{Record: &pb.CatalogRecord_Rating{Rating: &pb.RatingRecord{
+				LaptopId: laptopID,
+				Count:    rating.Count,
+				Sum:      rating.Sum,
+			}}})
+		})
+		if err != nil {
+			return exportErr(ctx, "cannot export ratings", err)
+		}
+	}
+
+	if server.imageStore != nil {
+		err = server.imageStore.Range(ctx, func(imageID string, info *ImageInfo) error {
+			data, err := os.ReadFile(info.Path)
+			if err != nil {
+				return status.Errorf(codes.Internal, "cannot read image %s: %v", imageID, err)
+			}
+			return send(&pb.CatalogRecord{Record: &pb.CatalogRecord_Image{Image: &pb.ImageRecord{
+				Id:        imageID,
+				LaptopId:  info.LaptopID,
+				ImageType: info.Type,
+				Data:      data,
+			}}})
+		})
+		if err != nil {
+			return exportErr(ctx, "cannot export images", err)
+		}
+	}
+
+	log.Printf("exported %d records", count)
+	return nil
+}
+
+func exportErr(ctx context.Context, msg string, err error) error {
+	if err := contextErr(ctx); err != nil {
+		return err
+	}
+	if _, ok := status.FromError(err); ok {
+		return logError(err)
+	}
+	return logError(status.Errorf(codes.Internal, "%s: %v", msg, err))
+}
+
+// ImportCatalog loads the records of a stream written by ExportCatalog
+func (server *LaptopServer) ImportCatalog(stream pb.LaptopService_ImportCatalogServer) error {
+	importer := &catalogImporter{
+		server:    server,
+		options:   &pb.ImportCatalogOptions{},
+		res:       &pb.ImportCatalogResponse{},
+		laptopIDs: make(map[string]bool),
+	}
+
+	for first := true; ; first = false {
+		if err := contextErr(stream.Context()); err != nil {
+			return err
+		}
+
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return logError(status.Errorf(codes.Unknown, "cannot receive stream request: %v", err))
+		}
+
+		if options := req.GetOptions(); options != nil {
+			if !first {
+				return logError(status.Error(codes.InvalidArgument, "options must be sent before any record"))
+			}
+			log.Printf("receive an import catalog request with options: %v", options)
+			importer.options = options
+			importer.res.DryRun = options.GetDryRun()
+			continue
+		}
+
+		err = importer.importRecord(req.GetRecord())
+		if err != nil {
+			return logError(err)
+		}
+	}
+
+	res := importer.res
+	log.Printf("imported catalog: %d created, %d overwritten, %d skipped, dry run = %t",
+		res.GetCreated(), res.GetOverwritten(), res.GetSkipped(), res.GetDryRun())
+	err := stream.SendAndClose(res)
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send response: %v", err))
+	}
+	return nil
+}
+
+type catalogImporter struct {
+	server  *LaptopServer
+	options *pb.ImportCatalogOptions
+	res     *pb.ImportCatalogResponse
+	// laptopIDs are the laptops of the stream, which a dry run doesn't write to the store
+	laptopIDs map[string]bool
+}
+
+func (importer *catalogImporter) importRecord(record *pb.CatalogRecord) error {
+	switch r := record.GetRecord().(type) {
+	case *pb.CatalogRecord_Laptop:
+		return importer.importLaptop(r.Laptop)
+	case *pb.CatalogRecord_Rating:
+		return importer.importRating(r.Rating)
+	case *pb.CatalogRecord_Image:
+		return importer.importImage(r.Image)
+	default:
+		return status.Error(codes.InvalidArgument, "record is empty")
+	}
+}
+
+// resolve tells whether a record should be written, according to the conflict policy
+func (importer *catalogImporter) resolve(kind string, id string, exists bool) (bool, error) {
+	if !exists {
+		importer.res.Created++
+		return !importer.options.GetDryRun(), nil
+	}
+
+	switch importer.options.GetOnConflict() {
+	case pb.ImportCatalogOptions_OVERWRITE:
+		importer.res.Overwritten++
+		return !importer.options.GetDryRun(), nil
+	case pb.ImportCatalogOptions_FAIL:
+		return false, status.Errorf(codes.AlreadyExists, "%s %s already exists", kind, id)
+	default:
+		importer.res.Skipped++
+		return false, nil
+	}
+}
+
+func (importer *catalogImporter) importLaptop(laptop *pb.Laptop) error {
+	_, err := uuid.Parse(laptop.GetId())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "laptop id %q is not a valid UUID: %v", laptop.GetId(), err)
+	}
+	// an import must not store what CreateLaptop and UpdateLaptop would reject
+	if violations := validate.LaptopViolations(laptop); len(violations) > 0 {
+		return validate.BadRequest("invalid laptop "+laptop.GetId(), violations)
+	}
+
+	laptopStore := importer.server.laptopStore
+	current, err := laptopStore.Find(laptop.GetId())
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+	}
+	importer.laptopIDs[laptop.GetId()] = true
+	write, err := importer.resolve("laptop", laptop.GetId(), current != nil)
+	if err != nil || !write {
+		return err
+	}
+
+	if current == nil {
+		err = laptopStore.Save(laptop)
+	} else {
+		err = laptopStore.Update(laptop, current.GetUpdatedAt())
+	}
+	if errors.Is(err, ErrConflict) {
+		return status.Errorf(codes.Aborted, "laptop %s was modified during the import", laptop.GetId())
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot save laptop: %v", err)
+	}
+	return nil
+}
+
+// checkLaptop returns an error unless the laptop is in the store or earlier in the stream,
+// so that ratings and images cannot point at a missing laptop
+func (importer *catalogImporter) checkLaptop(laptopID string, record string) error {
+	if importer.laptopIDs[laptopID] {
+		return nil
+	}
+	laptop, err := importer.server.laptopStore.Find(laptopID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+	}
+	if laptop == nil {
+		return status.Errorf(codes.InvalidArgument, "laptop %q of %s is not found", laptopID, record)
+	}
+	importer.laptopIDs[laptopID] = true
+	return nil
+}
+
+func (importer *catalogImporter) importRating(record *pb.RatingRecord) error {
+	ratingStore := importer.server.ratingStore
+	if ratingStore == nil {
+		return status.Error(codes.FailedPrecondition, "the server has no rating store")
+	}
+	err := importer.checkLaptop(record.GetLaptopId(), "rating")
+	if err != nil {
+		return err
+	}
+
+	current, err := ratingStore.Find(record.GetLaptopId())
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot find rating: %v", err)
+	}
+	write, err := importer.resolve("rating of laptop", record.GetLaptopId(), current != nil)
+	if err != nil || !write {
+		return err
+	}
+
+	err = ratingStore.Put(record.GetLaptopId(), &Rating{Count: record.GetCount(), Sum: record.GetSum()})
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot save rating: %v", err)
+	}
+	return nil
+}
+
+func (importer *catalogImporter) importImage(record *pb.ImageRecord) error {
+	imageStore := importer.server.imageStore
+	if imageStore == nil {
+		return status.Error(codes.FailedPrecondition, "the server has no image store")
+	}
+	_, err := uuid.Parse(record.GetId())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "image id %q is not a valid UUID: %v", record.GetId(), err)
+	}
+	if len(record.GetData()) > maxImageSize {
+		return status.Errorf(codes.InvalidArgument, "image %s is too large: %d > %d", record.GetId(), len(record.GetData()), maxImageSize)
+	}
+	err = importer.checkLaptop(record.GetLaptopId(), "image "+record.GetId())
+	if err != nil {
+		return err
+	}
+
+	current, err := imageStore.Find(record.GetId())
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot find image: %v", err)
+	}
+	write, err := importer.resolve("image", record.GetId(), current != nil)
+	if err != nil || !write {
+		return err
+	}
+
+	// the type is detected from the data, like for uploads, rather than taken from the record
+	err = imageStore.Restore(record.GetId(), record.GetLaptopId(), *bytes.NewBuffer(record.GetData()))
+	if errors.Is(err, imageformat.ErrUnsupported) {
+		return status.Errorf(codes.InvalidArgument, "image %s: %v", record.GetId(), err)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot save image: %v", err)
+	}
+	importer.server.makeThumbnails(record.GetId())
+	return nil
+}
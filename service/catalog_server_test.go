@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"example.com/pcbook/validate"
+	"github.com/google/uuid"
+	"github.com/test-go/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientImportCatalogInvalidRecords(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	imageStore, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	ratingStore := NewInMemoryRatingStore()
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterLaptopServiceServer(grpcServer, NewLaptopServer(laptopStore, imageStore, ratingStore))
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+	laptopClient := newTestLaptopClient(t, listener.Addr().String())
+
+	laptop := sample.NewLaptop()
+	laptopRecord := &pb.CatalogRecord{Record: &pb.CatalogRecord_Laptop{Laptop: laptop}}
+	imageRecord := func(laptopID string) *pb.CatalogRecord {
+		return &pb.CatalogRecord{Record: &pb.CatalogRecord_Image{Image: &pb.ImageRecord{
+			Id:       uuid.New().String(),
+			LaptopId: laptopID,
+			Data:     sample.NewPNG(10, 10),
+		}}}
+	}
+	ratingRecord := func(laptopID string) *pb.CatalogRecord {
+		return &pb.CatalogRecord{Record: &pb.CatalogRecord_Rating{Rating: &pb.RatingRecord{LaptopId: laptopID, Count: 1, Sum: 5}}}
+	}
+	missingID := sample.NewLaptop().GetId()
+	invalid := sample.NewLaptop()
+	invalid.PriceUsd = -1
+
+	testCases := []struct {
+		name    string
+		dryRun  bool
+		records []*pb.CatalogRecord
+		code    codes.Code
+		field   string
+	}{
+		{
+			name:    "dry_run_laptop_of_stream",
+			dryRun:  true,
+			records: []*pb.CatalogRecord{laptopRecord, ratingRecord(laptop.GetId()), imageRecord(laptop.GetId())},
+			code:    codes.OK,
+		},
+		{
+			name:    "invalid_laptop",
+			records: []*pb.CatalogRecord{{Record: &pb.CatalogRecord_Laptop{Laptop: invalid}}},
+			code:    codes.InvalidArgument,
+			field:   "price_usd",
+		},
+		{
+			name:    "image_of_missing_laptop",
+			records: []*pb.CatalogRecord{imageRecord(missingID)},
+			code:    codes.InvalidArgument,
+		},
+		{
+			name:    "rating_of_missing_laptop",
+			records: []*pb.CatalogRecord{ratingRecord(missingID)},
+			code:    codes.InvalidArgument,
+		},
+		{
+			name:    "laptop_of_stream",
+			records: []*pb.CatalogRecord{laptopRecord, imageRecord(laptop.GetId())},
+			code:    codes.OK,
+		},
+		{
+			name:    "laptop_of_store",
+			records: []*pb.CatalogRecord{imageRecord(laptop.GetId())},
+			code:    codes.OK,
+		},
+	}
+
+	for _, tc := range testCases {
+		stream, err := laptopClient.ImportCatalog(context.Background())
+		require.NoError(t, err)
+		err = stream.Send(&pb.ImportCatalogRequest{Data: &pb.ImportCatalogRequest_Options{Options: &pb.ImportCatalogOptions{DryRun: tc.dryRun}}})
+		require.NoError(t, err)
+		for _, record := range tc.records {
+			err = stream.Send(&pb.ImportCatalogRequest{Data: &pb.ImportCatalogRequest_Record{Record: record}})
+			require.NoError(t, err, tc.name)
+		}
+		_, err = stream.CloseAndRecv()
+		require.Equal(t, tc.code, status.Code(err), tc.name)
+		if tc.field != "" {
+			violations := validate.FieldViolations(err)
+			require.Len(t, violations, 1)
+			require.Equal(t, tc.field, violations[0].GetField())
+		}
+	}
+
+	images, err := imageStore.List(laptop.GetId())
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	found, err := laptopStore.Find(invalid.GetId())
+	require.NoError(t, err)
+	require.Nil(t, found)
+	images, err = imageStore.List(missingID)
+	require.NoError(t, err)
+	require.Empty(t, images)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// IdempotencyKeyHeader is the metadata header a client sets to make a retried request safe
+const IdempotencyKeyHeader = "idempotency-key"
+
+const DefaultIdempotencyWindow = 10 * time.Minute
+
+// IdempotencyCache remembers the responses of requests sent with an idempotency key,
+// so that a retry within the window gets the original response instead of running again.
+// Only successful responses are remembered: a failed request can be retried with the same key.
+type IdempotencyCache struct {
+	mutex   sync.Mutex
+	window  time.Duration
+	entries map[string]*idempotencyEntry
+	// expiries lists the keys in the order they expire, since the window is the same for all of them
+	expiries []idempotencyExpiry
+	now      func() time.Time
+}
+
+type idempotencyEntry struct {
+	fingerprint [sha256.Size]byte
+	// done is closed when the first request finishes
+	done     chan struct{}
+	response proto.Message
+	failed   bool
+}
+
+type idempotencyExpiry struct {
+	key       string
+	entry     *idempotencyEntry
+	expiresAt time.Time
+}
+
+func NewIdempotencyCache(window time.Duration) *IdempotencyCache {
+	return &IdempotencyCache{
+		window:  window,
+		entries: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+// idempotencyKey returns the key of the request, or an empty string if it has none
+func idempotencyKey(ctx context.Context) string {
+	values := metadata.ValueFromIncomingContext(ctx, IdempotencyKeyHeader)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// idempotencyScope returns who sent the request, so that the same key sent by different callers never collides:
+// the authenticated user, or the host of the peer for methods that need no role
+func idempotencyScope(ctx context.Context) string {
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		return "user:" + claims.Username
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		// the port changes with every connection, while a retry may come on a new one
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "peer:" + host
+	}
+	return ""
+}
+
+// fingerprint hashes the parts of a request, so that a retry can be told apart from another request
+func fingerprint(parts ...[]byte) [sha256.Size]byte {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write(part)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], hash.Sum(nil))
+	return sum
+}
+
+// do runs call unless a request of method from the same caller with the same key succeeded within the window,
+// in which case it returns a copy of that response. A request with the same key and another fingerprint is rejected.
+// Without a key in ctx, call simply runs.
+func (cache *IdempotencyCache) do(
+	ctx context.Context,
+	method string,
+	fingerprint [sha256.Size]byte,
+	call func() (proto.Message, error),
+) (proto.Message, error) {
+	key := idempotencyKey(ctx)
+	if cache == nil || key == "" {
+		return call()
+	}
+	key = method + "/" + idempotencyScope(ctx) + "/" + key
+
+	for {
+		cache.mutex.Lock()
+		cache.expire()
+		entry := cache.entries[key]
+		if entry == nil {
+			entry = &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+			cache.entries[key] = entry
+			cache.mutex.Unlock()
+			return cache.run(key, entry, call)
+		}
+		cache.mutex.Unlock()
+
+		if entry.fingerprint != fingerprint {
+			return nil, status.Errorf(codes.InvalidArgument, "idempotency key %q was already used with a different request", idempotencyKey(ctx))
+		}
+
+		// a retry may arrive while the first request is still running
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, contextErr(ctx)
+		}
+		if !entry.failed {
+			log.Printf("replay the response of idempotency key %s", key)
+			return proto.Clone(entry.response), nil
+		}
+		// the failed entry has been removed, so the next attempt runs the call again
+	}
+}
+
+func (cache *IdempotencyCache) run(key string, entry *idempotencyEntry, call func() (proto.Message, error)) (proto.Message, error) {
+	response, err := call()
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	defer close(entry.done)
+
+	if err != nil {
+		entry.failed = true
+		delete(cache.entries, key)
+		return nil, err
+	}
+	entry.response = proto.Clone(response)
+	cache.expiries = append(cache.expiries, idempotencyExpiry{
+		key:       key,
+		entry:     entry,
+		expiresAt: cache.now().Add(cache.window),
+	})
+	return response, nil
+}
+
+// expire removes the entries older than the window, the mutex must be held
+func (cache *IdempotencyCache) expire() {
+	now := cache.now()
+	n := 0
+	for n < len(cache.expiries) && !now.Before(cache.expiries[n].expiresAt) {
+		expiry := cache.expiries[n]
+		if cache.entries[expiry.key] == expiry.entry {
+			delete(cache.entries, expiry.key)
+		}
+		n++
+	}
+	cache.expiries = cache.expiries[n:]
+}
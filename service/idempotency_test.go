@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestClientIdempotentCreateLaptop(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	laptop := sample.NewLaptop()
+	laptop.Id = ""
+	req := &pb.CreateLaptopRequest{Laptop: laptop}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), IdempotencyKeyHeader, "key-1")
+
+	res1, err := laptopClient.CreateLaptop(ctx, req)
+	require.NoError(t, err)
+	res2, err := laptopClient.CreateLaptop(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, res1.GetId(), res2.GetId())
+
+	other := &pb.CreateLaptopRequest{Laptop: sample.NewLaptop()}
+	_, err = laptopClient.CreateLaptop(ctx, other)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), IdempotencyKeyHeader, "key-2")
+	res3, err := laptopClient.CreateLaptop(ctx, req)
+	require.NoError(t, err)
+	require.NotEqual(t, res1.GetId(), res3.GetId())
+}
+
+func TestClientIdempotentUploadImage(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	imageStore, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+
+	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), IdempotencyKeyHeader, "upload-1")
+
+	upload := func(data []byte) (*pb.UploadImageResponse, error) {
+		stream, err := laptopClient.UploadImage(ctx)
+		require.NoError(t, err)
+		err = stream.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_Info{Info: &pb.ImageInfo{
+			LaptopId:  laptop.GetId(),
+			ImageType: ".jpg",
+		}}})
+		require.NoError(t, err)
+		err = stream.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_ChunkData{ChunkData: data}})
+		require.NoError(t, err)
+		return stream.CloseAndRecv()
+	}
+
+	data, err := os.ReadFile("../tmp/laptop.jpg")
+	require.NoError(t, err)
+
+	res1, err := upload(data)
+	require.NoError(t, err)
+	res2, err := upload(data)
+	require.NoError(t, err)
+	require.Equal(t, res1.GetId(), res2.GetId())
+
+	count := 0
+	err = imageStore.Range(context.Background(), func(imageID string, info *ImageInfo) error {
+		count++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	_, err = upload(data[:len(data)/2])
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestIdempotencyCache(t *testing.T) {
+	t.Parallel()
+
+	cache := NewIdempotencyCache(time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "key"))
+	calls := 0
+	call := func() (proto.Message, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("failed")
+		}
+		return &pb.CreateLaptopResponse{Id: "id"}, nil
+	}
+
+	// a failure is not remembered
+	_, err := cache.do(ctx, "method", fingerprint([]byte("a")), call)
+	require.Error(t, err)
+	res, err := cache.do(ctx, "method", fingerprint([]byte("a")), call)
+	require.NoError(t, err)
+	require.Equal(t, "id", res.(*pb.CreateLaptopResponse).GetId())
+	require.Equal(t, 2, calls)
+
+	_, err = cache.do(ctx, "method", fingerprint([]byte("a")), call)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	// keys of different methods don't collide
+	_, err = cache.do(ctx, "other", fingerprint([]byte("b")), call)
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+
+	now = now.Add(time.Minute)
+	_, err = cache.do(ctx, "method", fingerprint([]byte("b")), call)
+	require.NoError(t, err)
+	require.Equal(t, 4, calls)
+
+	// without a key, every request runs
+	_, err = cache.do(context.Background(), "method", fingerprint([]byte("b")), call)
+	require.NoError(t, err)
+	require.Equal(t, 5, calls)
+
+	// the same key sent by different callers doesn't collide either
+	as := func(ctx context.Context, username string) context.Context {
+		return context.WithValue(ctx, claimsKey{}, &UserClaim{Username: username, Role: "admin"})
+	}
+	from := func(ctx context.Context, address string) context.Context {
+		return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(address), Port: 1000 + calls}})
+	}
+	for _, ctx := range []context.Context{as(ctx, "alice"), as(ctx, "bob"), from(ctx, "10.0.0.1"), from(ctx, "10.0.0.2")} {
+		_, err = cache.do(ctx, "method", fingerprint([]byte("c")), call)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 9, calls)
+
+	// a retry from the same user, or from the same host on another connection, is replayed
+	_, err = cache.do(as(ctx, "alice"), "method", fingerprint([]byte("c")), call)
+	require.NoError(t, err)
+	_, err = cache.do(from(ctx, "10.0.0.1"), "method", fingerprint([]byte("c")), call)
+	require.NoError(t, err)
+	require.Equal(t, 9, calls)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/serializer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// imageMetadataSuffix is appended to the image id to name the metadata file of an image
+const imageMetadataSuffix = ".meta.json"
+
+// ImageStoreReport lists what doesn't match between the files of an image folder and their metadata
+type ImageStoreReport struct {
+	// OrphanFiles are the files that no metadata points at
+	OrphanFiles []string
+	// MissingFiles are the metadata files that point at an image file which doesn't exist
+	MissingFiles []string
+	// InvalidMetadata are the metadata files that cannot be read
+	InvalidMetadata []string
+}
+
+func (report *ImageStoreReport) Consistent() bool {
+	return len(report.OrphanFiles) == 0 && len(report.MissingFiles) == 0 && len(report.InvalidMetadata) == 0
+}
+
+func (report *ImageStoreReport) log(folder string) {
+	for _, name := range report.OrphanFiles {
+		log.Printf("image file %s has no metadata", filepath.Join(folder, name))
+	}
+	for _, name := range report.MissingFiles {
+		log.Printf("image metadata %s points at a missing file", filepath.Join(folder, name))
+	}
+	for _, name := range report.InvalidMetadata {
+		log.Printf("image metadata %s cannot be read", filepath.Join(folder, name))
+	}
+}
+
+// Check compares the files in the image folder with their metadata
+func (store *DiskImageStore) Check() (*ImageStoreReport, error) {
+	report, _, err := scanImageFolder(store.ImageFoler)
+	return report, err
+}
+
+// scanImageFolder reads the metadata in folder and returns the images whose file exists
+func scanImageFolder(folder string) (*ImageStoreReport, []*ImageInfo, error) {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read image folder: %w", err)
+	}
+
+	files := make(map[string]bool)
+	var metadataNames []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), imageMetadataSuffix) {
+			metadataNames = append(metadataNames, entry.Name())
+		} else {
+			files[entry.Name()] = true
+		}
+	}
+
+	report := &ImageStoreReport{}
+	var images []*ImageInfo
+	for _, name := range metadataNames {
+		metadata := &pb.ImageMetadata{}
+		err := serializer.ReadProtobufFromJSONFileLenient(metadata, filepath.Join(folder, name))
+		if err != nil || metadata.GetId()+imageMetadataSuffix != name {
+			report.InvalidMetadata = append(report.InvalidMetadata, name)
+			continue
+		}
+
+		imageName := metadata.GetId() + metadata.GetImageType()
+		if !files[imageName] {
+			report.MissingFiles = append(report.MissingFiles, name)
+			continue
+		}
+		delete(files, imageName)
+
+		// the image is still served without the thumbnails that are missing
+		var thumbnails []ImageThumbnail
+		missing := false
+		for _, thumbnail := range metadata.GetThumbnails() {
+			thumbnailName := thumbnailName(metadata.GetId(), int(thumbnail.GetLongEdge()), metadata.GetImageType())
+			if !files[thumbnailName] {
+				missing = true
+				continue
+			}
+			delete(files, thumbnailName)
+			thumbnails = append(thumbnails, ImageThumbnail{
+				LongEdge: int(thumbnail.GetLongEdge()),
+				Width:    int(thumbnail.GetWidth()),
+				Height:   int(thumbnail.GetHeight()),
+				Path:     fmt.Sprintf("%s/%s", folder, thumbnailName),
+			})
+		}
+		if missing {
+			report.MissingFiles = append(report.MissingFiles, name)
+		}
+
+		images = append(images, &ImageInfo{
+			ID:         metadata.GetId(),
+			LaptopID:   metadata.GetLaptopId(),
+			Type:       metadata.GetImageType(),
+			MIMEType:   metadata.GetMimeType(),
+			Width:      int(metadata.GetWidth()),
+			Height:     int(metadata.GetHeight()),
+			Path:       fmt.Sprintf("%s/%s", folder, imageName),
+			Size:       int64(metadata.GetSize()),
+			Checksum:   metadata.GetChecksum(),
+			UploadedAt: metadata.GetUploadedAt().AsTime(),
+			Thumbnails: thumbnails,
+			position:   metadata.GetPosition(),
+		})
+	}
+
+	for name := range files {
+		report.OrphanFiles = append(report.OrphanFiles, name)
+	}
+	sort.Strings(report.OrphanFiles)
+	return report, images, nil
+}
+
+func thumbnailName(imageID string, longEdge int, imageType string) string {
+	return fmt.Sprintf("%s_%d%s", imageID, longEdge, imageType)
+}
+
+func imageMetadataPath(folder string, imageID string) string {
+	return fmt.Sprintf("%s/%s%s", folder, imageID, imageMetadataSuffix)
+}
+
+// writeImageMetadata replaces the metadata file of the image at once, so that a crash cannot leave half of it
+func writeImageMetadata(folder string, info *ImageInfo) error {
+	metadata := &pb.ImageMetadata{
+		Id:         info.ID,
+		LaptopId:   info.LaptopID,
+		ImageType:  info.Type,
+		Size:       uint64(info.Size),
+		Checksum:   info.Checksum,
+		UploadedAt: timestamppb.New(info.UploadedAt),
+		Position:   info.position,
+		MimeType:   info.MIMEType,
+		Width:      uint32(info.Width),
+		Height:     uint32(info.Height),
+	}
+	for _, thumbnail := range info.Thumbnails {
+		metadata.Thumbnails = append(metadata.Thumbnails, &pb.ImageThumbnail{
+			LongEdge: uint32(thumbnail.LongEdge),
+			Width:    uint32(thumbnail.Width),
+			Height:   uint32(thumbnail.Height),
+		})
+	}
+
+	path := imageMetadataPath(folder, info.ID)
+	tmpPath := path + ".tmp"
+	err := serializer.WriteProtobufToJSONFile(metadata, tmpPath)
+	if err != nil {
+		return fmt.Errorf("cannot write image metadata: %w", err)
+	}
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("cannot replace image metadata: %w", err)
+	}
+	return nil
+}
@@ -2,15 +2,46 @@ package service
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"sort"
 	"sync"
+	"time"
 
+	"example.com/pcbook/imageformat"
 	"github.com/google/uuid"
 )
 
+// ErrImageOrder is returned when a new order of the images of a laptop doesn't have each of them exactly once
+var ErrImageOrder = errors.New("the order must have every image of the laptop once")
+
 type ImageStore interface {
-	Save(laptopId string, imageType string, imageData bytes.Buffer) (string, error)
+	// Save detects the format of the image from its content, it returns an error wrapping
+	// imageformat.ErrUnsupported if the image is not in one of the accepted formats
+	Save(laptopId string, imageData bytes.Buffer) (string, error)
+	// Find returns nil if there is no image with that id
+	Find(imageID string) (*ImageInfo, error)
+	// List returns the images of a laptop in gallery order, the first one is its primary image
+	List(laptopID string) ([]*ImageInfo, error)
+	// Delete removes the image and its file, ErrNotFound if there is no image with that id
+	Delete(imageID string) error
+	// SetPrimary moves the image to the front of the gallery of its laptop
+	SetPrimary(imageID string) error
+	// Reorder changes the gallery order of a laptop, imageIDs must have every image of the laptop
+	Reorder(laptopID string, imageIDs []string) error
+	// Range calls found for every image, in ascending order of laptop id and then in gallery order,
+	// so that restoring the images in that order keeps the galleries
+	Range(ctx context.Context, found func(imageID string, info *ImageInfo) error) error
+	// Restore saves an image under a known id, replacing any image with the same id
+	Restore(imageID string, laptopID string, imageData bytes.Buffer) error
+	// SaveThumbnails stores smaller copies of an image, by long edge. It returns ErrNotFound
+	// if the image was deleted, or replaced since the thumbnails were made from the image with checksum.
+	SaveThumbnails(imageID string, checksum string, thumbnails map[int][]byte) error
 }
 
 type DiskImageStore struct {
@@ -18,43 +49,400 @@ type DiskImageStore struct {
 
 	ImageFoler string
 	images     map[string]*ImageInfo
+	// galleries maps a laptop id to the ids of its images, in gallery order
+	galleries map[string][]string
 }
 
 type ImageInfo struct {
+	ID       string
 	LaptopID string
-	Type     string
+	// Type is the file extension of the format of the image
+	Type       string
+	MIMEType   string
+	Width      int
+	Height     int
+	Path       string
+	Size       int64
+	Checksum   string
+	UploadedAt time.Time
+	// Thumbnails are sorted by long edge
+	Thumbnails []ImageThumbnail
+
+	position uint32
+}
+
+// ImageThumbnail is a smaller copy of an image, in the same format
+type ImageThumbnail struct {
+	LongEdge int
+	Width    int
+	Height   int
 	Path     string
 }
 
-func NewDiskImageStore(imageFolder string) *DiskImageStore {
-	return &DiskImageStore{
+// Thumbnail returns the smallest thumbnail with at least the given long edge, nil if there is none
+func (info *ImageInfo) Thumbnail(longEdge int) *ImageThumbnail {
+	for i := range info.Thumbnails {
+		if info.Thumbnails[i].LongEdge >= longEdge {
+			return &info.Thumbnails[i]
+		}
+	}
+	return nil
+}
+
+func (info *ImageInfo) clone() *ImageInfo {
+	other := *info
+	other.Thumbnails = append([]ImageThumbnail(nil), info.Thumbnails...)
+	return &other
+}
+
+// removeFiles removes the files of the image and its thumbnails
+func (info *ImageInfo) removeFiles() {
+	removeImageFile(info.Path)
+	info.removeThumbnails()
+}
+
+func (info *ImageInfo) removeThumbnails() {
+	for _, thumbnail := range info.Thumbnails {
+		removeImageFile(thumbnail.Path)
+	}
+}
+
+func removeImageFile(path string) {
+	err := os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("cannot remove image file %s: %v", path, err)
+	}
+}
+
+// NewDiskImageStore indexes the images in imageFolder from the metadata written next to them.
+// The problems that Check would report are logged, and the images they concern are left out.
+func NewDiskImageStore(imageFolder string) (*DiskImageStore, error) {
+	err := os.MkdirAll(imageFolder, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create image folder: %w", err)
+	}
+
+	report, images, err := scanImageFolder(imageFolder)
+	if err != nil {
+		return nil, err
+	}
+	report.log(imageFolder)
+
+	store := &DiskImageStore{
 		ImageFoler: imageFolder,
 		images:     make(map[string]*ImageInfo),
+		galleries:  make(map[string][]string),
 	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].position == images[j].position {
+			return images[i].ID < images[j].ID
+		}
+		return images[i].position < images[j].position
+	})
+	for _, info := range images {
+		store.images[info.ID] = info
+		store.galleries[info.LaptopID] = append(store.galleries[info.LaptopID], info.ID)
+	}
+	log.Printf("indexed %d images in %s", len(images), imageFolder)
+	return store, nil
 }
 
-func (store *DiskImageStore) Save(laptopID string, imageType string, imageData bytes.Buffer) (string, error) {
+func (store *DiskImageStore) Save(laptopID string, imageData bytes.Buffer) (string, error) {
 	imageID, err := uuid.NewRandom()
 	if err != nil {
 		return "", fmt.Errorf("cannot generate image id: %w", err)
 	}
-	imagePath := fmt.Sprintf("%s/%s%s", store.ImageFoler, imageID, imageType)
-	file, err := os.Create(imagePath)
+
+	err = store.Restore(imageID.String(), laptopID, imageData)
 	if err != nil {
-		return "", fmt.Errorf("cannot create image file: %w", err)
+		return "", err
 	}
-	_, err = imageData.WriteTo(file)
+	return imageID.String(), nil
+}
+
+func (store *DiskImageStore) Restore(imageID string, laptopID string, imageData bytes.Buffer) error {
+	// the extension is derived from the content, so that clients cannot choose where the file goes
+	format, err := imageformat.Detect(imageData.Bytes())
 	if err != nil {
-		return "", fmt.Errorf("cannot write image to file: %w", err)
+		return err
+	}
+
+	checksum := sha256.Sum256(imageData.Bytes())
+	info := &ImageInfo{
+		ID:         imageID,
+		LaptopID:   laptopID,
+		Type:       format.Extension,
+		MIMEType:   format.MIMEType,
+		Width:      format.Width,
+		Height:     format.Height,
+		Path:       fmt.Sprintf("%s/%s%s", store.ImageFoler, imageID, format.Extension),
+		Size:       int64(imageData.Len()),
+		Checksum:   hex.EncodeToString(checksum[:]),
+		UploadedAt: time.Now(),
+	}
+
+	// the data is written to a file of its own, then moved in place under the lock,
+	// so that concurrent restores of the same image never write to the same file
+	file, err := os.CreateTemp(store.ImageFoler, imageID+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create image file: %w", err)
+	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath)
+
+	err = file.Chmod(0644)
+	if err == nil {
+		_, err = imageData.WriteTo(file)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write image to file: %w", err)
 	}
 
 	store.mutex.Lock()
 	defer store.mutex.Unlock()
 
-	store.images[imageID.String()] = &ImageInfo{
-		LaptopID: laptopID,
-		Type:     imageType,
-		Path:     imagePath,
+	err = os.Rename(tmpPath, info.Path)
+	if err != nil {
+		return fmt.Errorf("cannot move image file: %w", err)
 	}
-	return imageID.String(), nil
+
+	old := store.images[imageID]
+	if old != nil && old.LaptopID == laptopID {
+		info.position = old.position
+	} else {
+		info.position = store.nextPosition(laptopID)
+	}
+
+	err = writeImageMetadata(store.ImageFoler, info)
+	if err != nil {
+		if old == nil || old.Path != info.Path {
+			os.Remove(info.Path)
+		}
+		return err
+	}
+
+	if old != nil {
+		// the thumbnails are of the old content, and an image restored with another type leaves its old file behind
+		old.removeThumbnails()
+		if old.Path != info.Path {
+			removeImageFile(old.Path)
+		}
+		if old.LaptopID != laptopID {
+			store.removeFromGallery(old.LaptopID, imageID)
+			old = nil
+		}
+	}
+	if old == nil {
+		store.galleries[laptopID] = append(store.galleries[laptopID], imageID)
+	}
+	store.images[imageID] = info
+	return nil
+}
+
+func (store *DiskImageStore) SaveThumbnails(imageID string, checksum string, thumbnails map[int][]byte) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	info := store.images[imageID]
+	if info == nil || info.Checksum != checksum {
+		return ErrNotFound
+	}
+
+	updated := info.clone()
+	updated.Thumbnails = nil
+	for longEdge, data := range thumbnails {
+		format, err := imageformat.Detect(data)
+		if err != nil {
+			return fmt.Errorf("invalid %dpx thumbnail: %w", longEdge, err)
+		}
+		if format.Extension != info.Type {
+			return fmt.Errorf("%dpx thumbnail is %s, not %s like the image", longEdge, format.MIMEType, info.MIMEType)
+		}
+		updated.Thumbnails = append(updated.Thumbnails, ImageThumbnail{
+			LongEdge: longEdge,
+			Width:    format.Width,
+			Height:   format.Height,
+			Path:     fmt.Sprintf("%s/%s", store.ImageFoler, thumbnailName(imageID, longEdge, info.Type)),
+		})
+	}
+	sort.Slice(updated.Thumbnails, func(i, j int) bool {
+		return updated.Thumbnails[i].LongEdge < updated.Thumbnails[j].LongEdge
+	})
+
+	for _, thumbnail := range updated.Thumbnails {
+		err := os.WriteFile(thumbnail.Path, thumbnails[thumbnail.LongEdge], 0644)
+		if err != nil {
+			return fmt.Errorf("cannot write thumbnail: %w", err)
+		}
+	}
+	err := writeImageMetadata(store.ImageFoler, updated)
+	if err != nil {
+		return err
+	}
+	for _, thumbnail := range info.Thumbnails {
+		if _, ok := thumbnails[thumbnail.LongEdge]; !ok {
+			removeImageFile(thumbnail.Path)
+		}
+	}
+	store.images[imageID] = updated
+	return nil
+}
+
+// nextPosition returns the position after the last image of the gallery, the mutex must be locked
+func (store *DiskImageStore) nextPosition(laptopID string) uint32 {
+	gallery := store.galleries[laptopID]
+	if len(gallery) == 0 {
+		return 0
+	}
+	return store.images[gallery[len(gallery)-1]].position + 1
+}
+
+// saveGallery numbers the images of the gallery from 0 in its order and writes the metadata that changed,
+// the mutex must be locked
+func (store *DiskImageStore) saveGallery(laptopID string, gallery []string) error {
+	for i, imageID := range gallery {
+		info := store.images[imageID]
+		if info.position == uint32(i) {
+			continue
+		}
+		updated := *info
+		updated.position = uint32(i)
+		err := writeImageMetadata(store.ImageFoler, &updated)
+		if err != nil {
+			return err
+		}
+		store.images[imageID] = &updated
+	}
+	store.galleries[laptopID] = gallery
+	return nil
+}
+
+// removeFromGallery must be called with the mutex locked
+func (store *DiskImageStore) removeFromGallery(laptopID string, imageID string) {
+	gallery := store.galleries[laptopID]
+	for i, id := range gallery {
+		if id == imageID {
+			gallery = append(gallery[:i:i], gallery[i+1:]...)
+			break
+		}
+	}
+	if len(gallery) == 0 {
+		delete(store.galleries, laptopID)
+		return
+	}
+	store.galleries[laptopID] = gallery
+}
+
+func (store *DiskImageStore) Find(imageID string) (*ImageInfo, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	info := store.images[imageID]
+	if info == nil {
+		return nil, nil
+	}
+	return info.clone(), nil
+}
+
+func (store *DiskImageStore) List(laptopID string) ([]*ImageInfo, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	gallery := store.galleries[laptopID]
+	images := make([]*ImageInfo, 0, len(gallery))
+	for _, imageID := range gallery {
+		images = append(images, store.images[imageID].clone())
+	}
+	return images, nil
+}
+
+func (store *DiskImageStore) Delete(imageID string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	info := store.images[imageID]
+	if info == nil {
+		return ErrNotFound
+	}
+	// without its metadata the image is no longer indexed on startup, even if removing the file fails
+	err := os.Remove(imageMetadataPath(store.ImageFoler, imageID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot remove image metadata: %w", err)
+	}
+	info.removeFiles()
+
+	delete(store.images, imageID)
+	store.removeFromGallery(info.LaptopID, imageID)
+	return nil
+}
+
+func (store *DiskImageStore) SetPrimary(imageID string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	info := store.images[imageID]
+	if info == nil {
+		return ErrNotFound
+	}
+	gallery := []string{imageID}
+	for _, id := range store.galleries[info.LaptopID] {
+		if id != imageID {
+			gallery = append(gallery, id)
+		}
+	}
+	return store.saveGallery(info.LaptopID, gallery)
+}
+
+func (store *DiskImageStore) Reorder(laptopID string, imageIDs []string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	gallery := store.galleries[laptopID]
+	if len(imageIDs) != len(gallery) {
+		return ErrImageOrder
+	}
+	if len(gallery) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(imageIDs))
+	for _, imageID := range imageIDs {
+		info := store.images[imageID]
+		if info == nil || info.LaptopID != laptopID || seen[imageID] {
+			return ErrImageOrder
+		}
+		seen[imageID] = true
+	}
+
+	return store.saveGallery(laptopID, append([]string(nil), imageIDs...))
+}
+
+func (store *DiskImageStore) Range(ctx context.Context, found func(imageID string, info *ImageInfo) error) error {
+	// copy the index, so that found doesn't hold up new uploads
+	store.mutex.RLock()
+	laptopIDs := make([]string, 0, len(store.galleries))
+	for laptopID := range store.galleries {
+		laptopIDs = append(laptopIDs, laptopID)
+	}
+	sort.Strings(laptopIDs)
+	images := make([]ImageInfo, 0, len(store.images))
+	for _, laptopID := range laptopIDs {
+		for _, imageID := range store.galleries[laptopID] {
+			images = append(images, *store.images[imageID].clone())
+		}
+	}
+	store.mutex.RUnlock()
+
+	for i := range images {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := found(images[i].ID, &images[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+)
+
+func TestDiskImageStoreGallery(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	laptopID1 := "11111111-1111-1111-1111-111111111111"
+	laptopID2 := "22222222-2222-2222-2222-222222222222"
+
+	save := func(laptopID string) string {
+		imageID, err := store.Save(laptopID, *bytes.NewBuffer(sample.NewPNG(2, 2)))
+		require.NoError(t, err)
+		return imageID
+	}
+	image1, image2, image3 := save(laptopID2), save(laptopID2), save(laptopID1)
+
+	require.NoError(t, store.SetPrimary(image2))
+	require.Equal(t, ErrImageOrder, store.Reorder(laptopID2, []string{image2, image2}))
+	require.Equal(t, ErrImageOrder, store.Reorder(laptopID2, []string{image2, image3}))
+
+	// Range visits every gallery in order, so restoring in that order keeps them
+	restored, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	var ranged []string
+	err = store.Range(context.Background(), func(imageID string, info *ImageInfo) error {
+		ranged = append(ranged, imageID)
+		return restored.Restore(imageID, info.LaptopID, *bytes.NewBuffer(sample.NewPNG(2, 2)))
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{image3, image2, image1}, ranged)
+
+	images, err := restored.List(laptopID2)
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	require.Equal(t, image2, images[0].ID)
+	require.Equal(t, image1, images[1].ID)
+
+	// restoring an image for another laptop moves it to that gallery
+	require.NoError(t, restored.Restore(image1, laptopID1, *bytes.NewBuffer(sample.NewPNG(2, 2))))
+	images, err = restored.List(laptopID1)
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	require.Equal(t, image1, images[1].ID)
+	images, err = restored.List(laptopID2)
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+
+	require.NoError(t, restored.Delete(image2))
+	images, err = restored.List(laptopID2)
+	require.NoError(t, err)
+	require.Empty(t, images)
+	require.Equal(t, ErrNotFound, restored.Delete(image2))
+}
+
+func TestDiskImageStoreReopen(t *testing.T) {
+	t.Parallel()
+
+	folder := t.TempDir()
+	store, err := NewDiskImageStore(folder)
+	require.NoError(t, err)
+	laptopID := "11111111-1111-1111-1111-111111111111"
+
+	var imageIDs []string
+	for i := 0; i < 4; i++ {
+		imageID, err := store.Save(laptopID, *bytes.NewBuffer(sample.NewPNG(i+1, 1)))
+		require.NoError(t, err)
+		imageIDs = append(imageIDs, imageID)
+	}
+	require.NoError(t, store.Delete(imageIDs[1]))
+	require.NoError(t, store.SetPrimary(imageIDs[3]))
+	// the new image goes after the others, despite the gap left by the deleted one
+	imageID, err := store.Save(laptopID, *bytes.NewBuffer(sample.NewPNG(5, 1)))
+	require.NoError(t, err)
+	imageIDs = append(imageIDs, imageID)
+
+	report, err := store.Check()
+	require.NoError(t, err)
+	require.True(t, report.Consistent())
+
+	reopened, err := NewDiskImageStore(folder)
+	require.NoError(t, err)
+	images, err := reopened.List(laptopID)
+	require.NoError(t, err)
+	require.Len(t, images, 4)
+	for i, imageID := range []string{imageIDs[3], imageIDs[0], imageIDs[2], imageIDs[4]} {
+		require.Equal(t, imageID, images[i].ID)
+	}
+	expected, err := store.Find(imageIDs[0])
+	require.NoError(t, err)
+	require.Equal(t, expected.Checksum, images[1].Checksum)
+	require.Equal(t, expected.Size, images[1].Size)
+	require.Equal(t, "image/png", images[1].MIMEType)
+	require.Equal(t, 1, images[1].Width)
+	require.True(t, expected.UploadedAt.Equal(images[1].UploadedAt))
+
+	// files added or removed behind the back of the store
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "orphan.jpg"), []byte("image"), 0644))
+	require.NoError(t, os.Remove(images[0].Path))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "broken"+imageMetadataSuffix), []byte("{"), 0644))
+
+	report, err = reopened.Check()
+	require.NoError(t, err)
+	require.False(t, report.Consistent())
+	require.Equal(t, []string{"orphan.jpg"}, report.OrphanFiles)
+	require.Equal(t, []string{imageIDs[3] + imageMetadataSuffix}, report.MissingFiles)
+	require.Equal(t, []string{"broken" + imageMetadataSuffix}, report.InvalidMetadata)
+
+	reopened, err = NewDiskImageStore(folder)
+	require.NoError(t, err)
+	images, err = reopened.List(laptopID)
+	require.NoError(t, err)
+	require.Len(t, images, 3)
+	require.Equal(t, imageIDs[0], images[0].ID)
+}
+
+func TestDiskImageStoreConcurrentRestore(t *testing.T) {
+	t.Parallel()
+
+	folder := t.TempDir()
+	store, err := NewDiskImageStore(folder)
+	require.NoError(t, err)
+	imageID := "33333333-3333-3333-3333-333333333333"
+	laptopID := "11111111-1111-1111-1111-111111111111"
+
+	errs := make(chan error)
+	for i := 1; i <= 8; i++ {
+		data := sample.NewPNG(i*10, i*10)
+		go func() {
+			errs <- store.Restore(imageID, laptopID, *bytes.NewBuffer(data))
+		}()
+	}
+	for i := 1; i <= 8; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// the file is the content of the last restore, whichever it was
+	info, err := store.Find(imageID)
+	require.NoError(t, err)
+	data, err := os.ReadFile(info.Path)
+	require.NoError(t, err)
+	checksum := sha256.Sum256(data)
+	require.Equal(t, info.Checksum, hex.EncodeToString(checksum[:]))
+	require.EqualValues(t, len(data), info.Size)
+
+	report, err := store.Check()
+	require.NoError(t, err)
+	require.True(t, report.Consistent())
+	entries, err := os.ReadDir(folder)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
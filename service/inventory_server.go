@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"example.com/pcbook/pb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultReservationTTL = 15 * time.Minute
+	maxReservationTTL     = 24 * time.Hour
+)
+
+type InventoryServer struct {
+	pb.UnimplementedInventoryServiceServer
+	laptopStore LaptopStore
+	stockStore  StockStore
+}
+
+func NewInventoryServer(laptopStore LaptopStore, stockStore StockStore) *InventoryServer {
+	return &InventoryServer{laptopStore: laptopStore, stockStore: stockStore}
+}
+
+func (server *InventoryServer) SetStock(ctx context.Context, req *pb.SetStockRequest) (*pb.SetStockResponse, error) {
+	log.Printf("receive a set stock request: %v", req)
+
+	if req.GetWarehouse() == "" {
+		return nil, status.Error(codes.InvalidArgument, "warehouse must be provided")
+	}
+	err := server.checkLaptop(req.GetLaptopId())
+	if err != nil {
+		return nil, err
+	}
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+
+	stock, err := server.stockStore.Set(req.GetLaptopId(), req.GetWarehouse(), req.GetOnHand())
+	if err != nil {
+		return nil, stockErr("cannot set stock", err)
+	}
+	return &pb.SetStockResponse{Stock: stock}, nil
+}
+
+func (server *InventoryServer) GetStock(ctx context.Context, req *pb.GetStockRequest) (*pb.GetStockResponse, error) {
+	log.Printf("receive a get stock request for laptop %s", req.GetLaptopId())
+
+	err := server.checkLaptop(req.GetLaptopId())
+	if err != nil {
+		return nil, err
+	}
+
+	stocks, err := server.stockStore.Find(req.GetLaptopId())
+	if err != nil {
+		return nil, stockErr("cannot find stock", err)
+	}
+	res := &pb.GetStockResponse{Stocks: stocks}
+	for _, stock := range stocks {
+		res.Available += stock.GetOnHand() - stock.GetReserved()
+	}
+	return res, nil
+}
+
+func (server *InventoryServer) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	log.Printf("receive a reserve stock request: %v", req)
+
+	if req.GetQuantity() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "quantity must be positive")
+	}
+	ttl := time.Duration(req.GetTtlSeconds()) * time.Second
+	if ttl == 0 {
+		ttl = DefaultReservationTTL
+	}
+	if ttl > maxReservationTTL {
+		return nil, status.Errorf(codes.InvalidArgument, "ttl cannot be longer than %v", maxReservationTTL)
+	}
+	err := server.checkLaptop(req.GetLaptopId())
+	if err != nil {
+		return nil, err
+	}
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+
+	reservation, err := server.stockStore.Reserve(req.GetLaptopId(), req.GetWarehouse(), req.GetQuantity(), ttl)
+	if err != nil {
+		return nil, stockErr("cannot reserve stock", err)
+	}
+	log.Printf("reserved %d units of laptop %s with id: %s", reservation.GetQuantity(), reservation.GetLaptopId(), reservation.GetId())
+	return &pb.ReserveStockResponse{Reservation: reservation}, nil
+}
+
+func (server *InventoryServer) CommitReservation(ctx context.Context, req *pb.CommitReservationRequest) (*pb.CommitReservationResponse, error) {
+	log.Printf("receive a commit reservation request with id: %s", req.GetReservationId())
+
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+	stock, err := server.stockStore.Commit(req.GetReservationId())
+	if err != nil {
+		return nil, stockErr("cannot commit reservation", err)
+	}
+	return &pb.CommitReservationResponse{Stock: stock}, nil
+}
+
+func (server *InventoryServer) ReleaseReservation(ctx context.Context, req *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+	log.Printf("receive a release reservation request with id: %s", req.GetReservationId())
+
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+	stock, err := server.stockStore.Release(req.GetReservationId())
+	if err != nil {
+		return nil, stockErr("cannot release reservation", err)
+	}
+	return &pb.ReleaseReservationResponse{Stock: stock}, nil
+}
+
+// checkLaptop makes sure that stock is only kept for laptops of the catalog
+func (server *InventoryServer) checkLaptop(laptopID string) error {
+	_, err := uuid.Parse(laptopID)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "laptop Id is not a valid UUID: %v", err)
+	}
+	laptop, err := server.laptopStore.Find(laptopID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+	}
+	if laptop == nil {
+		return status.Errorf(codes.NotFound, "laptop %s is not found", laptopID)
+	}
+	return nil
+}
+
+func stockErr(msg string, err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, ErrOutOfStock), errors.Is(err, ErrBelowReserved):
+		code = codes.FailedPrecondition
+	}
+	return logError(status.Errorf(code, "%s: %v", msg, err))
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientInventory(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	stockStore := NewInMemoryStockStore(0)
+	defer stockStore.Close()
+
+	inStock := sample.NewLaptop()
+	outOfStock := sample.NewLaptop()
+	for _, laptop := range []*pb.Laptop{inStock, outOfStock} {
+		laptop.PriceUsd = 2000
+		require.NoError(t, laptopStore.Save(laptop))
+	}
+
+	laptopServer := NewLaptopServer(laptopStore, nil, nil)
+	laptopServer.SetStockStore(stockStore)
+	grpcServer := grpc.NewServer()
+	pb.RegisterLaptopServiceServer(grpcServer, laptopServer)
+	pb.RegisterInventoryServiceServer(grpcServer, NewInventoryServer(laptopStore, stockStore))
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	laptopClient := pb.NewLaptopServiceClient(conn)
+	inventoryClient := pb.NewInventoryServiceClient(conn)
+	ctx := context.Background()
+
+	searchInStock := func() []string {
+		stream, err := laptopClient.SearchLaptop(ctx, &pb.SearchLaptopRequest{
+			Filter: &pb.Filter{MaxPriceUsd: 3000, InStockOnly: true},
+		})
+		require.NoError(t, err)
+		var ids []string
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				return ids
+			}
+			require.NoError(t, err)
+			ids = append(ids, res.GetLaptop().GetId())
+		}
+	}
+
+	_, err = inventoryClient.SetStock(ctx, &pb.SetStockRequest{LaptopId: inStock.GetId(), Warehouse: "berlin", OnHand: 2})
+	require.NoError(t, err)
+	_, err = inventoryClient.SetStock(ctx, &pb.SetStockRequest{LaptopId: sample.NewLaptop().GetId(), Warehouse: "berlin", OnHand: 2})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Equal(t, []string{inStock.GetId()}, searchInStock())
+
+	aggregated, err := laptopClient.AggregateLaptops(ctx, &pb.AggregateLaptopsRequest{Filter: &pb.Filter{InStockOnly: true}})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), aggregated.GetTotal())
+
+	watch, err := laptopClient.WatchLaptops(ctx, &pb.WatchRequest{Filter: &pb.Filter{InStockOnly: true}})
+	require.NoError(t, err)
+	_, err = watch.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	reserved, err := inventoryClient.ReserveStock(ctx, &pb.ReserveStockRequest{LaptopId: inStock.GetId(), Quantity: 2})
+	require.NoError(t, err)
+	_, err = inventoryClient.ReserveStock(ctx, &pb.ReserveStockRequest{LaptopId: inStock.GetId(), Quantity: 1})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.Empty(t, searchInStock())
+
+	stock, err := inventoryClient.GetStock(ctx, &pb.GetStockRequest{LaptopId: inStock.GetId()})
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), stock.GetAvailable())
+
+	_, err = inventoryClient.ReleaseReservation(ctx, &pb.ReleaseReservationRequest{ReservationId: reserved.GetReservation().GetId()})
+	require.NoError(t, err)
+	require.Equal(t, []string{inStock.GetId()}, searchInStock())
+
+	reserved, err = inventoryClient.ReserveStock(ctx, &pb.ReserveStockRequest{LaptopId: inStock.GetId(), Quantity: 1})
+	require.NoError(t, err)
+	committed, err := inventoryClient.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: reserved.GetReservation().GetId()})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), committed.GetStock().GetOnHand())
+
+	_, err = inventoryClient.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: reserved.GetReservation().GetId()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
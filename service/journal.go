@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/serializer"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// journal is an append-only file of length-delimited JournalEntry records, plus a snapshot file
+// that the records are compacted into. It is not safe for concurrent use, the owning store serializes access.
+type journal struct {
+	journalPath  string
+	snapshotPath string
+	file         *os.File
+	size         int64
+}
+
+// openJournal replays the snapshot and then the journal of the given name in dir through apply.
+// apply must be idempotent: after a crash during compaction, entries already in the snapshot are replayed again.
+func openJournal(dir string, name string, apply func(entry *pb.JournalEntry)) (*journal, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create journal directory: %w", err)
+	}
+
+	j := &journal{
+		journalPath:  filepath.Join(dir, name+".journal"),
+		snapshotPath: filepath.Join(dir, name+".snapshot"),
+	}
+
+	_, err = replay(j.snapshotPath, apply)
+	if err != nil {
+		return nil, fmt.Errorf("cannot replay snapshot: %w", err)
+	}
+
+	valid, err := replay(j.journalPath, apply)
+	if err != nil {
+		torn, tornErr := tornTail(j.journalPath, valid)
+		if tornErr != nil {
+			return nil, tornErr
+		}
+		if !torn {
+			return nil, fmt.Errorf("cannot replay journal: %w", err)
+		}
+		log.Printf("dropping torn record at offset %d of %s: %v", valid, j.journalPath, err)
+	}
+
+	file, err := os.OpenFile(j.journalPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open journal file: %w", err)
+	}
+	j.file = file
+
+	// cut off a record torn by a crash so that new records follow the last valid one
+	err = j.truncate(valid)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+func replay(path string, apply func(entry *pb.JournalEntry)) (int64, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		entry := &pb.JournalEntry{}
+		n, err := serializer.ReadProtobufFromBinaryStream(entry, reader)
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, fmt.Errorf("cannot read record at offset %d of %s: %w", offset, path, err)
+		}
+		if entry.GetEntry() == nil {
+			// zeros read as empty records, every record that is written has an entry
+			return offset, fmt.Errorf("empty record at offset %d of %s", offset, path)
+		}
+		apply(entry)
+		offset += int64(n)
+	}
+}
+
+// tornTail reports whether the bytes of the file from offset are what a crash during an append leaves behind:
+// a single record that is cut or not fully written, or zeros where the file grew before its data was written.
+// Anything else is corruption in the middle of the journal, which must not be dropped silently.
+func tornTail(path string, offset int64) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return false, fmt.Errorf("cannot seek %s: %w", path, err)
+	}
+	tail, err := io.ReadAll(file)
+	if err != nil {
+		return false, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+
+	if len(bytes.Trim(tail, "\x00")) == 0 {
+		return true, nil
+	}
+	size, n := protowire.ConsumeVarint(tail)
+	if n < 0 {
+		return len(tail) < binary.MaxVarintLen64, nil
+	}
+	return uint64(n)+size >= uint64(len(tail)), nil
+}
+
+func (j *journal) append(entry *pb.JournalEntry) error {
+	err := serializer.WriteProtobufToBinaryStream(entry, j.file)
+	if err == nil {
+		err = j.file.Sync()
+	}
+	if err != nil {
+		// drop whatever part of the record made it to the file
+		if truncateErr := j.truncate(j.size); truncateErr != nil {
+			log.Print(truncateErr)
+		}
+		return fmt.Errorf("cannot append to journal: %w", err)
+	}
+
+	info, err := j.file.Stat()
+	if err != nil {
+		return fmt.Errorf("cannot stat journal file: %w", err)
+	}
+	j.size = info.Size()
+	return nil
+}
+
+// compact writes every record produced by snapshot to a new snapshot file and empties the journal
+func (j *journal) compact(snapshot func(write func(entry *pb.JournalEntry) error) error) error {
+	tmpPath := j.snapshotPath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("cannot create snapshot file: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	writer := bufio.NewWriter(file)
+	err = snapshot(func(entry *pb.JournalEntry) error {
+		return serializer.WriteProtobufToBinaryStream(entry, writer)
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("cannot write snapshot: %w", err)
+	}
+
+	err = os.Rename(tmpPath, j.snapshotPath)
+	if err != nil {
+		return fmt.Errorf("cannot replace snapshot: %w", err)
+	}
+	// the rename must be durable before the journal is emptied, or a crash could lose both
+	err = syncDir(filepath.Dir(j.snapshotPath))
+	if err != nil {
+		return err
+	}
+	return j.truncate(0)
+}
+
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("cannot open directory: %w", err)
+	}
+	defer file.Close()
+
+	err = file.Sync()
+	if err != nil {
+		return fmt.Errorf("cannot sync directory: %w", err)
+	}
+	return nil
+}
+
+func (j *journal) truncate(size int64) error {
+	err := j.file.Truncate(size)
+	if err != nil {
+		return fmt.Errorf("cannot truncate journal file: %w", err)
+	}
+	_, err = j.file.Seek(size, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("cannot seek journal file: %w", err)
+	}
+	j.size = size
+	return nil
+}
+
+func (j *journal) close() error {
+	return j.file.Close()
+}
+
+func scheduleCompaction(interval time.Duration, done <-chan struct{}, compact func() error) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := compact()
+				if err != nil {
+					log.Printf("cannot compact journal: %v", err)
+				}
+			}
+		}
+	}()
+}
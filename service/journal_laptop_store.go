@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"example.com/pcbook/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// JournalLaptopStore keeps laptops in memory and appends every change to a journal file,
+// which is replayed when the store is opened again
+type JournalLaptopStore struct {
+	mutex   sync.Mutex
+	memory  *InMemoryLaptopStore
+	journal *journal
+	done    chan struct{}
+}
+
+func NewJournalLaptopStore(dir string, compactInterval time.Duration) (*JournalLaptopStore, error) {
+	store := &JournalLaptopStore{
+		memory: NewInMemoryLaptopStore(),
+		done:   make(chan struct{}),
+	}
+
+	journal, err := openJournal(dir, "laptops", store.apply)
+	if err != nil {
+		return nil, err
+	}
+	store.journal = journal
+
+	scheduleCompaction(compactInterval, store.done, store.Compact)
+	return store, nil
+}
+
+func (store *JournalLaptopStore) apply(entry *pb.JournalEntry) {
+	switch e := entry.GetEntry().(type) {
+	case *pb.JournalEntry_SaveLaptop:
+		store.memory.put(e.SaveLaptop)
+	case *pb.JournalEntry_UpdateLaptop:
+		store.memory.put(e.UpdateLaptop)
+	case *pb.JournalEntry_DeleteLaptopId:
+		store.memory.remove(e.DeleteLaptopId)
+	}
+}
+
+func (store *JournalLaptopStore) Save(laptop *pb.Laptop) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	found, err := store.memory.Find(laptop.Id)
+	if err != nil {
+		return err
+	}
+	if found != nil {
+		return ErrAlreadyExists
+	}
+
+	err = store.journal.append(&pb.JournalEntry{
+		Entry: &pb.JournalEntry_SaveLaptop{SaveLaptop: laptop},
+	})
+	if err != nil {
+		return err
+	}
+	return store.memory.Save(laptop)
+}
+
+func (store *JournalLaptopStore) Update(laptop *pb.Laptop, lastUpdatedAt *timestamppb.Timestamp) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	current, err := store.memory.Find(laptop.Id)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrNotFound
+	}
+	if !proto.Equal(current.GetUpdatedAt(), lastUpdatedAt) {
+		return ErrConflict
+	}
+
+	err = store.journal.append(&pb.JournalEntry{
+		Entry: &pb.JournalEntry_UpdateLaptop{UpdateLaptop: laptop},
+	})
+	if err != nil {
+		return err
+	}
+	return store.memory.Update(laptop, lastUpdatedAt)
+}
+
+func (store *JournalLaptopStore) Delete(id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	found, err := store.memory.Find(id)
+	if err != nil {
+		return err
+	}
+	if found == nil {
+		return ErrNotFound
+	}
+
+	err = store.journal.append(&pb.JournalEntry{
+		Entry: &pb.JournalEntry_DeleteLaptopId{DeleteLaptopId: id},
+	})
+	if err != nil {
+		return err
+	}
+	return store.memory.Delete(id)
+}
+
+func (store *JournalLaptopStore) Find(id string) (*pb.Laptop, error) {
+	return store.memory.Find(id)
+}
+
+func (store *JournalLaptopStore) Search(ctx context.Context, filter *pb.Filter, afterID string, found func(laptop *pb.Laptop) error) error {
+	return store.memory.Search(ctx, filter, afterID, found)
+}
+
+func (store *JournalLaptopStore) Subscribe() *LaptopSubscription {
+	return store.memory.Subscribe()
+}
+
+func (store *JournalLaptopStore) MatchText(text string) (map[string]float64, error) {
+	return store.memory.MatchText(text)
+}
+
+// Compact writes all current laptops to a snapshot and empties the journal
+func (store *JournalLaptopStore) Compact() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.journal.compact(func(write func(entry *pb.JournalEntry) error) error {
+		store.memory.mutex.RLock()
+		defer store.memory.mutex.RUnlock()
+
+		for _, laptop := range store.memory.data {
+			err := write(&pb.JournalEntry{
+				Entry: &pb.JournalEntry_SaveLaptop{SaveLaptop: laptop},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (store *JournalLaptopStore) Close() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	close(store.done)
+	return store.journal.close()
+}
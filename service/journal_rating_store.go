@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"example.com/pcbook/pb"
+)
+
+// JournalRatingStore keeps ratings in memory and appends every change to a journal file,
+// which is replayed when the store is opened again
+type JournalRatingStore struct {
+	mutex   sync.Mutex
+	memory  *InMemoryRatingStore
+	journal *journal
+	done    chan struct{}
+}
+
+func NewJournalRatingStore(dir string, compactInterval time.Duration) (*JournalRatingStore, error) {
+	store := &JournalRatingStore{
+		memory: NewInMemoryRatingStore(),
+		done:   make(chan struct{}),
+	}
+
+	journal, err := openJournal(dir, "ratings", store.apply)
+	if err != nil {
+		return nil, err
+	}
+	store.journal = journal
+
+	scheduleCompaction(compactInterval, store.done, store.Compact)
+	return store, nil
+}
+
+func (store *JournalRatingStore) apply(entry *pb.JournalEntry) {
+	record := entry.GetRating()
+	if record == nil {
+		return
+	}
+	store.memory.rating[record.GetLaptopId()] = &Rating{
+		Count: record.GetCount(),
+		Sum:   record.GetSum(),
+	}
+}
+
+func (store *JournalRatingStore) Add(laptopID string, score float64) (*Rating, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	// the journal records the resulting totals, so replaying a record twice is harmless
+	record := &pb.RatingRecord{
+		LaptopId: laptopID,
+		Count:    1,
+		Sum:      score,
+	}
+	store.memory.mutex.RLock()
+	if rating := store.memory.rating[laptopID]; rating != nil {
+		record.Count += rating.Count
+		record.Sum += rating.Sum
+	}
+	store.memory.mutex.RUnlock()
+
+	err := store.journal.append(&pb.JournalEntry{
+		Entry: &pb.JournalEntry_Rating{Rating: record},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store.memory.Add(laptopID, score)
+}
+
+func (store *JournalRatingStore) Find(laptopID string) (*Rating, error) {
+	return store.memory.Find(laptopID)
+}
+
+func (store *JournalRatingStore) Put(laptopID string, rating *Rating) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	err := store.journal.append(&pb.JournalEntry{
+		Entry: &pb.JournalEntry_Rating{Rating: &pb.RatingRecord{
+			LaptopId: laptopID,
+			Count:    rating.Count,
+			Sum:      rating.Sum,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	return store.memory.Put(laptopID, rating)
+}
+
+func (store *JournalRatingStore) Range(ctx context.Context, found func(laptopID string, rating *Rating) error) error {
+	return store.memory.Range(ctx, found)
+}
+
+// Compact writes all current ratings to a snapshot and empties the journal
+func (store *JournalRatingStore) Compact() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.journal.compact(func(write func(entry *pb.JournalEntry) error) error {
+		store.memory.mutex.RLock()
+		defer store.memory.mutex.RUnlock()
+
+		for laptopID, rating := range store.memory.rating {
+			err := write(&pb.JournalEntry{
+				Entry: &pb.JournalEntry_Rating{Rating: &pb.RatingRecord{
+					LaptopId: laptopID,
+					Count:    rating.Count,
+					Sum:      rating.Sum,
+				}},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (store *JournalRatingStore) Close() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	close(store.done)
+	return store.journal.close()
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"example.com/pcbook/serializer"
+	"github.com/test-go/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestJournalLaptopStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := NewJournalLaptopStore(dir, 0)
+	require.NoError(t, err)
+
+	laptop1 := sample.NewLaptop()
+	laptop2 := sample.NewLaptop()
+	require.NoError(t, store.Save(laptop1))
+	require.NoError(t, store.Save(laptop2))
+	require.Equal(t, ErrAlreadyExists, store.Save(laptop1))
+
+	updated := proto.Clone(laptop1).(*pb.Laptop)
+	updated.PriceUsd = 999
+	updated.UpdatedAt = timestamppb.Now()
+	require.NoError(t, store.Update(updated, laptop1.GetUpdatedAt()))
+	require.NoError(t, store.Delete(laptop2.Id))
+	require.NoError(t, store.Close())
+
+	// simulate a crash in the middle of appending a record
+	journalPath := filepath.Join(dir, "laptops.journal")
+	file, err := os.OpenFile(journalPath, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.Write([]byte{0x7f, 0x0a, 0x01})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	store, err = NewJournalLaptopStore(dir, 0)
+	require.NoError(t, err)
+
+	other, err := store.Find(laptop1.Id)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(updated, other))
+	other, err = store.Find(laptop2.Id)
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	// new records must follow the last valid one
+	laptop3 := sample.NewLaptop()
+	require.NoError(t, store.Save(laptop3))
+	require.NoError(t, store.Compact())
+
+	info, err := os.Stat(journalPath)
+	require.NoError(t, err)
+	require.Zero(t, info.Size())
+	require.NoError(t, store.Close())
+
+	store, err = NewJournalLaptopStore(dir, 0)
+	require.NoError(t, err)
+	defer store.Close()
+
+	for _, laptop := range []*pb.Laptop{updated, laptop3} {
+		other, err := store.Find(laptop.Id)
+		require.NoError(t, err)
+		require.True(t, proto.Equal(laptop, other))
+	}
+}
+
+func TestJournalRatingStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := NewJournalRatingStore(dir, 0)
+	require.NoError(t, err)
+
+	laptopID := sample.NewLaptop().Id
+	_, err = store.Add(laptopID, 8)
+	require.NoError(t, err)
+	require.NoError(t, store.Compact())
+	_, err = store.Add(laptopID, 7)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = NewJournalRatingStore(dir, 0)
+	require.NoError(t, err)
+
+	rating, err := store.Add(laptopID, 9)
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), rating.Count)
+	require.Equal(t, 24.0, rating.Sum)
+
+	// a put replaces the totals, also after a replay
+	require.NoError(t, store.Put(laptopID, &Rating{Count: 1, Sum: 5}))
+	require.NoError(t, store.Close())
+
+	store, err = NewJournalRatingStore(dir, 0)
+	require.NoError(t, err)
+	defer store.Close()
+
+	rating, err = store.Find(laptopID)
+	require.NoError(t, err)
+	require.Equal(t, &Rating{Count: 1, Sum: 5}, rating)
+}
+
+func TestJournalTornTail(t *testing.T) {
+	t.Parallel()
+
+	laptop := sample.NewLaptop()
+	record := bytes.Buffer{}
+	require.NoError(t, serializer.WriteProtobufToBinaryStream(&pb.JournalEntry{Entry: &pb.JournalEntry_SaveLaptop{SaveLaptop: sample.NewLaptop()}}, &record))
+
+	testCases := []struct {
+		name string
+		tail []byte
+		torn bool
+	}{
+		{name: "cut_record", tail: record.Bytes()[:record.Len()/2], torn: true},
+		{name: "cut_size", tail: []byte{0xff}, torn: true},
+		{name: "zeros", tail: make([]byte, 4096), torn: true},
+		{name: "garbage_record", tail: []byte{0x03, 0xff, 0xff, 0xff}, torn: true},
+		{name: "garbage_before_record", tail: append([]byte{0x03, 0xff, 0xff, 0xff}, record.Bytes()...), torn: false},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		store, err := NewJournalLaptopStore(dir, 0)
+		require.NoError(t, err)
+		require.NoError(t, store.Save(laptop))
+		require.NoError(t, store.Close())
+
+		journalPath := filepath.Join(dir, "laptops.journal")
+		info, err := os.Stat(journalPath)
+		require.NoError(t, err)
+		file, err := os.OpenFile(journalPath, os.O_APPEND|os.O_WRONLY, 0644)
+		require.NoError(t, err)
+		_, err = file.Write(tc.tail)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		store, err = NewJournalLaptopStore(dir, 0)
+		if !tc.torn {
+			require.Error(t, err, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+		other, err := store.Find(laptop.GetId())
+		require.NoError(t, err)
+		require.True(t, proto.Equal(laptop, other), tc.name)
+		require.NoError(t, store.Close())
+
+		// the tail is cut off at the last valid record
+		truncated, err := os.Stat(journalPath)
+		require.NoError(t, err)
+		require.Equal(t, info.Size(), truncated.Size(), tc.name)
+	}
+}
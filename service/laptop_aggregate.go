@@ -0,0 +1,209 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"example.com/pcbook/memsize"
+	"example.com/pcbook/pb"
+)
+
+var allFacets = []pb.AggregateLaptopsRequest_Facet{
+	pb.AggregateLaptopsRequest_BRAND,
+	pb.AggregateLaptopsRequest_CPU_BRAND,
+	pb.AggregateLaptopsRequest_SCREEN_PANEL,
+	pb.AggregateLaptopsRequest_KEYBOARD_LAYOUT,
+}
+
+// laptopAggregator accumulates the facet counts, histograms and statistics of the laptops passed to add
+type laptopAggregator struct {
+	total          uint32
+	facets         []pb.AggregateLaptopsRequest_Facet
+	counts         map[pb.AggregateLaptopsRequest_Facet]map[string]uint32
+	priceHistogram *histogram
+	ramHistogram   *histogram
+
+	priceUsd       numericStats
+	ramGB          numericStats
+	cpuCores       numericStats
+	cpuMinGhz      numericStats
+	screenSizeInch numericStats
+	weightKg       numericStats
+	releaseYear    numericStats
+}
+
+func newLaptopAggregator(req *pb.AggregateLaptopsRequest) (*laptopAggregator, error) {
+	aggregator := &laptopAggregator{
+		counts: make(map[pb.AggregateLaptopsRequest_Facet]map[string]uint32),
+	}
+	facets := req.GetFacets()
+	if len(facets) == 0 {
+		facets = allFacets
+	}
+	for _, facet := range facets {
+		if _, ok := pb.AggregateLaptopsRequest_Facet_name[int32(facet)]; !ok || facet == pb.AggregateLaptopsRequest_UNKNOWN {
+			return nil, fmt.Errorf("unknown facet: %d", facet)
+		}
+		// a facet asked for twice is returned once
+		if _, ok := aggregator.counts[facet]; ok {
+			continue
+		}
+		aggregator.facets = append(aggregator.facets, facet)
+		aggregator.counts[facet] = make(map[string]uint32)
+	}
+
+	var err error
+	aggregator.priceHistogram, err = newHistogram(req.GetPriceBucketsUsd())
+	if err != nil {
+		return nil, fmt.Errorf("invalid price buckets: %w", err)
+	}
+	aggregator.ramHistogram, err = newHistogram(req.GetRamBucketsGb())
+	if err != nil {
+		return nil, fmt.Errorf("invalid RAM buckets: %w", err)
+	}
+	return aggregator, nil
+}
+
+func (aggregator *laptopAggregator) add(laptop *pb.Laptop) error {
+	aggregator.total++
+
+	for facet, counts := range aggregator.counts {
+		counts[facetValue(facet, laptop)]++
+	}
+
+	ramGB := memsize.InUnit(laptop.GetRam(), pb.Memory_GIGABYTE)
+	aggregator.priceHistogram.add(laptop.GetPriceUsd())
+	aggregator.ramHistogram.add(ramGB)
+
+	aggregator.priceUsd.add(laptop.GetPriceUsd())
+	aggregator.ramGB.add(ramGB)
+	aggregator.cpuCores.add(float64(laptop.GetCpu().GetNumberCores()))
+	aggregator.cpuMinGhz.add(laptop.GetCpu().GetMinGhz())
+	// unknown sizes, weights and years are left out of the statistics
+	if size := laptop.GetScreen().GetSizeInch(); size > 0 {
+		aggregator.screenSizeInch.add(float64(size))
+	}
+	if weight := weightKg(laptop); weight > 0 {
+		aggregator.weightKg.add(weight)
+	}
+	if year := laptop.GetReleaseYear(); year > 0 {
+		aggregator.releaseYear.add(float64(year))
+	}
+	return nil
+}
+
+func (aggregator *laptopAggregator) response() *pb.AggregateLaptopsResponse {
+	res := &pb.AggregateLaptopsResponse{
+		Total:          aggregator.total,
+		PriceHistogram: aggregator.priceHistogram.buckets(),
+		RamHistogram:   aggregator.ramHistogram.buckets(),
+		PriceUsd:       aggregator.priceUsd.proto(),
+		RamGb:          aggregator.ramGB.proto(),
+		CpuCores:       aggregator.cpuCores.proto(),
+		CpuMinGhz:      aggregator.cpuMinGhz.proto(),
+		ScreenSizeInch: aggregator.screenSizeInch.proto(),
+		WeightKg:       aggregator.weightKg.proto(),
+		ReleaseYear:    aggregator.releaseYear.proto(),
+	}
+
+	for _, facet := range aggregator.facets {
+		facetCounts := &pb.FacetCounts{Facet: facet}
+		for value, count := range aggregator.counts[facet] {
+			facetCounts.Counts = append(facetCounts.Counts, &pb.FacetCount{Value: value, Count: count})
+		}
+		sort.Slice(facetCounts.Counts, func(i, j int) bool {
+			a, b := facetCounts.Counts[i], facetCounts.Counts[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.Value < b.Value
+		})
+		res.Facets = append(res.Facets, facetCounts)
+	}
+	return res
+}
+
+func facetValue(facet pb.AggregateLaptopsRequest_Facet, laptop *pb.Laptop) string {
+	switch facet {
+	case pb.AggregateLaptopsRequest_BRAND:
+		return laptop.GetBrand()
+	case pb.AggregateLaptopsRequest_CPU_BRAND:
+		return laptop.GetCpu().GetBrand()
+	case pb.AggregateLaptopsRequest_SCREEN_PANEL:
+		return laptop.GetScreen().GetPanel().String()
+	case pb.AggregateLaptopsRequest_KEYBOARD_LAYOUT:
+		return laptop.GetKeyboard().GetLayout().String()
+	default:
+		return ""
+	}
+}
+
+// histogram counts values into buckets starting at each bound, values below the first bound are not counted
+type histogram struct {
+	bounds []float64
+	counts []uint32
+}
+
+func newHistogram(bounds []float64) (*histogram, error) {
+	for i, bound := range bounds {
+		if math.IsNaN(bound) || math.IsInf(bound, 0) {
+			return nil, fmt.Errorf("bound %v is not a number", bound)
+		}
+		if i > 0 && bound <= bounds[i-1] {
+			return nil, fmt.Errorf("bounds must be in ascending order")
+		}
+	}
+	return &histogram{bounds: bounds, counts: make([]uint32, len(bounds))}, nil
+}
+
+func (h *histogram) add(value float64) {
+	// index of the last bound that is not above value
+	i := sort.Search(len(h.bounds), func(i int) bool { return h.bounds[i] > value }) - 1
+	if i >= 0 {
+		h.counts[i]++
+	}
+}
+
+func (h *histogram) buckets() []*pb.HistogramBucket {
+	buckets := make([]*pb.HistogramBucket, 0, len(h.bounds))
+	for i, bound := range h.bounds {
+		bucket := &pb.HistogramBucket{From: bound, Count: h.counts[i]}
+		if i+1 < len(h.bounds) {
+			bucket.To = h.bounds[i+1]
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+type numericStats struct {
+	count uint32
+	min   float64
+	max   float64
+	sum   float64
+}
+
+func (stats *numericStats) add(value float64) {
+	if stats.count == 0 || value < stats.min {
+		stats.min = value
+	}
+	if stats.count == 0 || value > stats.max {
+		stats.max = value
+	}
+	stats.count++
+	stats.sum += value
+}
+
+// proto returns nil when no value was added
+func (stats *numericStats) proto() *pb.NumericStats {
+	if stats.count == 0 {
+		return nil
+	}
+	return &pb.NumericStats{
+		Count:   stats.count,
+		Min:     stats.min,
+		Max:     stats.max,
+		Average: stats.sum / float64(stats.count),
+	}
+}
@@ -2,19 +2,28 @@ package service
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
+	"time"
 
+	"example.com/pcbook/client"
 	"example.com/pcbook/pb"
 	"example.com/pcbook/sample"
 	"example.com/pcbook/serializer"
 	"github.com/test-go/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/code"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestClientCreatelaptop(t *testing.T) {
@@ -27,6 +36,8 @@ func TestClientCreatelaptop(t *testing.T) {
 	laptopClient := newTestLaptopClient(t, serverAddress)
 
 	laptop := sample.NewLaptop()
+	forged := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	laptop.UpdatedAt = timestamppb.New(forged)
 
 	expectedId := laptop.Id
 	req := &pb.CreateLaptopRequest{
@@ -42,6 +53,10 @@ func TestClientCreatelaptop(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, other)
 
+	// the server stamps updated_at, whatever the client sends
+	require.True(t, other.GetUpdatedAt().AsTime().After(forged))
+	laptop.UpdatedAt = other.GetUpdatedAt()
+
 	//check that the saved latpop is the same as the one send
 	requireSameLaptop(t, laptop, other)
 
@@ -122,16 +137,189 @@ func TestClientSearchlaptop(t *testing.T) {
 	require.Equal(t, found, len(expectedIds))
 }
 
+func TestClientSearchLaptopPagination(t *testing.T) {
+	t.Parallel()
+
+	filter := &pb.Filter{
+		MaxPriceUsd: 3000,
+	}
+
+	laptopStore := NewInMemoryLaptopStore()
+	expectedIds := make([]string, 0, 5)
+	for i := 0; i < 5; i++ {
+		laptop := sample.NewLaptop()
+		err := laptopStore.Save(laptop)
+		require.NoError(t, err)
+		expectedIds = append(expectedIds, laptop.Id)
+	}
+	sort.Strings(expectedIds)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	foundIds := make([]string, 0, 5)
+	pageToken := ""
+	for pages := 1; ; pages++ {
+		req := &pb.SearchLaptopRequest{Filter: filter, PageSize: 2, PageToken: pageToken}
+		stream, err := laptopClient.SearchLaptop(context.Background(), req)
+		require.NoError(t, err)
+
+		pageToken = ""
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			foundIds = append(foundIds, res.GetLaptop().GetId())
+			pageToken = res.GetNextPageToken()
+		}
+		if pageToken == "" {
+			require.Equal(t, 3, pages)
+			break
+		}
+	}
+	require.Equal(t, expectedIds, foundIds)
+}
+
+func TestClientSearchLaptopOrderBy(t *testing.T) {
+	t.Parallel()
+
+	filter := &pb.Filter{
+		MaxPriceUsd: 3000,
+	}
+	orderBy := &pb.OrderBy{
+		Field:      pb.OrderBy_PRICE,
+		Descending: true,
+	}
+
+	laptopStore := NewInMemoryLaptopStore()
+	prices := []float64{1500, 2900, 2100, 1800, 2500}
+	for _, price := range prices {
+		laptop := sample.NewLaptop()
+		laptop.PriceUsd = price
+		err := laptopStore.Save(laptop)
+		require.NoError(t, err)
+	}
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	foundPrices := make([]float64, 0, len(prices))
+	pageToken := ""
+	for {
+		req := &pb.SearchLaptopRequest{Filter: filter, OrderBy: orderBy, PageSize: 2, PageToken: pageToken}
+		stream, err := laptopClient.SearchLaptop(context.Background(), req)
+		require.NoError(t, err)
+
+		pageToken = ""
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			foundPrices = append(foundPrices, res.GetLaptop().GetPriceUsd())
+			pageToken = res.GetNextPageToken()
+		}
+		if pageToken == "" {
+			break
+		}
+	}
+	require.Equal(t, []float64{2900, 2500, 2100, 1800, 1500}, foundPrices)
+}
+
+func TestClientSearchLaptopOrderByIDDescending(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	expectedIds := make([]string, 0, 5)
+	for i := 0; i < 5; i++ {
+		laptop := sample.NewLaptop()
+		err := laptopStore.Save(laptop)
+		require.NoError(t, err)
+		expectedIds = append(expectedIds, laptop.GetId())
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(expectedIds)))
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	orderBy := &pb.OrderBy{Field: pb.OrderBy_ID, Descending: true}
+	foundIds := make([]string, 0, len(expectedIds))
+	pageToken := ""
+	for {
+		req := &pb.SearchLaptopRequest{Filter: exportFilter, OrderBy: orderBy, PageSize: 2, PageToken: pageToken}
+		stream, err := laptopClient.SearchLaptop(context.Background(), req)
+		require.NoError(t, err)
+
+		pageToken = ""
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			foundIds = append(foundIds, res.GetLaptop().GetId())
+			pageToken = res.GetNextPageToken()
+		}
+		if pageToken == "" {
+			break
+		}
+	}
+	require.Equal(t, expectedIds, foundIds)
+}
+
+func TestClientSearchLaptopText(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	names := []struct{ brand, name string }{
+		{"Lenovo", "Thinkpad X1"},
+		{"Apple", "Macbook Pro"},
+		{"Lenovo", "Thinkpad P1"},
+	}
+	ids := make([]string, 0, len(names))
+	for _, n := range names {
+		laptop := sample.NewLaptop()
+		laptop.Brand = n.brand
+		laptop.Name = n.name
+		err := laptopStore.Save(laptop)
+		require.NoError(t, err)
+		ids = append(ids, laptop.GetId())
+	}
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	// no filter, the text alone selects the laptops and orders them by relevance
+	req := &pb.SearchLaptopRequest{Text: "thinkpad p1"}
+	stream, err := laptopClient.SearchLaptop(context.Background(), req)
+	require.NoError(t, err)
+
+	foundIds := make([]string, 0, len(ids))
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		foundIds = append(foundIds, res.GetLaptop().GetId())
+	}
+	require.Equal(t, []string{ids[2], ids[0]}, foundIds)
+}
+
 func TestClientUploadImage(t *testing.T) {
 	t.Parallel()
 
 	testImgFolder := "../tmp"
 
 	laptopStore := NewInMemoryLaptopStore()
-	imageStore := NewDiskImageStore(testImgFolder)
+	imageStore, err := NewDiskImageStore(testImgFolder)
+	require.NoError(t, err)
 
 	laptop := sample.NewLaptop()
-	err := laptopStore.Save(laptop)
+	err = laptopStore.Save(laptop)
 	require.NoError(t, err)
 
 	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
@@ -187,8 +375,176 @@ func TestClientUploadImage(t *testing.T) {
 	_, err = os.Stat(saveImagePath)
 
 	require.NoError(t, err)
-	require.NoError(t, os.Remove(saveImagePath))
+	require.NoError(t, imageStore.Delete(res.GetId()))
+
+}
+
+func TestClientUploadImageType(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	imageFolder := t.TempDir()
+	imageStore, err := NewDiskImageStore(imageFolder)
+	require.NoError(t, err)
+
+	laptop := sample.NewLaptop()
+	err = laptopStore.Save(laptop)
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	upload := func(imageType string, data []byte) (*pb.UploadImageResponse, error) {
+		stream, err := laptopClient.UploadImage(context.Background())
+		require.NoError(t, err)
+		err = stream.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_Info{Info: &pb.ImageInfo{
+			LaptopId:  laptop.GetId(),
+			ImageType: imageType,
+		}}})
+		require.NoError(t, err)
+		err = stream.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_ChunkData{ChunkData: data}})
+		require.NoError(t, err)
+		return stream.CloseAndRecv()
+	}
+
+	// the extension comes from the content, whatever the client says
+	res, err := upload("/../../evil.sh", sample.NewPNG(4, 3))
+	require.NoError(t, err)
+	info, err := imageStore.Find(res.GetId())
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%s/%s.png", imageFolder, res.GetId()), info.Path)
+	require.Equal(t, "image/png", info.MIMEType)
+	require.Equal(t, 4, info.Width)
+	require.Equal(t, 3, info.Height)
+
+	_, err = upload(".jpg", []byte("#!/bin/sh\necho not an image\n"))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestClientDownloadImage(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	imageStore, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+
+	laptop := sample.NewLaptop()
+	err = laptopStore.Save(laptop)
+	require.NoError(t, err)
+
+	// larger than a chunk, so that it is sent in several
+	data := sample.NewPNG(128, 128)
+	imageID, err := imageStore.Save(laptop.GetId(), *bytes.NewBuffer(data))
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	stream, err := laptopClient.DownloadImage(context.Background(), &pb.DownloadImageRequest{ImageId: imageID})
+	require.NoError(t, err)
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, laptop.GetId(), res.GetInfo().GetLaptopId())
+	require.Equal(t, ".png", res.GetInfo().GetImageType())
+	require.Equal(t, "image/png", res.GetInfo().GetMimeType())
+	require.EqualValues(t, 128, res.GetInfo().GetWidth())
+	require.EqualValues(t, 128, res.GetInfo().GetHeight())
+	require.EqualValues(t, len(data), res.GetInfo().GetSize())
+
+	var downloaded []byte
+	chunks := 0
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		downloaded = append(downloaded, res.GetChunkData()...)
+		chunks++
+	}
+	require.Equal(t, data, downloaded)
+	require.Equal(t, (len(data)+imageChunkSize-1)/imageChunkSize, chunks)
+
+	stream, err = laptopClient.DownloadImage(context.Background(), &pb.DownloadImageRequest{ImageId: "unknown"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestClientLaptopImages(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	imageStore, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+
+	laptop := sample.NewLaptop()
+	laptop.PriceUsd = 2000
+	err = laptopStore.Save(laptop)
+	require.NoError(t, err)
+
+	var imageIDs []string
+	for i := 0; i < 3; i++ {
+		imageID, err := imageStore.Save(laptop.GetId(), *bytes.NewBuffer(sample.NewPNG(1, 1)))
+		require.NoError(t, err)
+		imageIDs = append(imageIDs, imageID)
+	}
+
+	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+	ctx := context.Background()
+
+	requireGallery := func(images []*pb.LaptopImage, expectedIDs ...string) {
+		require.Len(t, images, len(expectedIDs))
+		for i, image := range images {
+			require.Equal(t, expectedIDs[i], image.GetId())
+			require.Equal(t, i == 0, image.GetPrimary())
+		}
+	}
+
+	listed, err := laptopClient.ListLaptopImages(ctx, &pb.ListLaptopImagesRequest{LaptopId: laptop.GetId()})
+	require.NoError(t, err)
+	requireGallery(listed.GetImages(), imageIDs...)
+
+	primary, err := laptopClient.SetPrimaryImage(ctx, &pb.SetPrimaryImageRequest{ImageId: imageIDs[2]})
+	require.NoError(t, err)
+	requireGallery(primary.GetImages(), imageIDs[2], imageIDs[0], imageIDs[1])
 
+	stream, err := laptopClient.SearchLaptop(ctx, &pb.SearchLaptopRequest{Filter: &pb.Filter{MaxPriceUsd: 3000}})
+	require.NoError(t, err)
+	found, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, imageIDs[2], found.GetPrimaryImageId())
+
+	_, err = laptopClient.ReorderImages(ctx, &pb.ReorderImagesRequest{LaptopId: laptop.GetId(), ImageIds: imageIDs[:2]})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	reordered, err := laptopClient.ReorderImages(ctx, &pb.ReorderImagesRequest{
+		LaptopId: laptop.GetId(),
+		ImageIds: []string{imageIDs[1], imageIDs[2], imageIDs[0]},
+	})
+	require.NoError(t, err)
+	requireGallery(reordered.GetImages(), imageIDs[1], imageIDs[2], imageIDs[0])
+
+	_, err = laptopClient.DeleteImage(ctx, &pb.DeleteImageRequest{ImageId: imageIDs[1]})
+	require.NoError(t, err)
+	_, err = laptopClient.DeleteImage(ctx, &pb.DeleteImageRequest{ImageId: imageIDs[1]})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	listed, err = laptopClient.ListLaptopImages(ctx, &pb.ListLaptopImagesRequest{LaptopId: laptop.GetId()})
+	require.NoError(t, err)
+	requireGallery(listed.GetImages(), imageIDs[2], imageIDs[0])
+
+	info, err := imageStore.Find(imageIDs[0])
+	require.NoError(t, err)
+	_, err = laptopClient.DeleteLaptop(ctx, &pb.DeleteLaptopRequest{Id: laptop.GetId()})
+	require.NoError(t, err)
+	images, err := imageStore.List(laptop.GetId())
+	require.NoError(t, err)
+	require.Empty(t, images)
+	_, err = os.Stat(info.Path)
+	require.True(t, os.IsNotExist(err))
+
+	_, err = laptopClient.ListLaptopImages(ctx, &pb.ListLaptopImagesRequest{LaptopId: laptop.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(err))
 }
 
 func TestClientRateLaptop(t *testing.T) {
@@ -268,3 +624,261 @@ func requireSameLaptop(t *testing.T, laptop1, laptop2 *pb.Laptop) {
 
 	require.Equal(t, json1, json2)
 }
+
+func TestClientWatchLaptops(t *testing.T) {
+	t.Parallel()
+
+	filter := &pb.Filter{
+		MaxPriceUsd: 3000,
+	}
+
+	laptopStore := NewInMemoryLaptopStore()
+	current := sample.NewLaptop()
+	err := laptopStore.Save(current)
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := laptopClient.WatchLaptops(ctx, &pb.WatchRequest{Filter: filter})
+	require.NoError(t, err)
+
+	requireEvent := func(event pb.WatchResponse_Event, id string) *pb.WatchResponse {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, event, res.GetEvent())
+		require.Equal(t, id, res.GetLaptopId())
+		return res
+	}
+	requireEvent(pb.WatchResponse_CURRENT, current.Id)
+
+	created := sample.NewLaptop()
+	err = laptopStore.Save(created)
+	require.NoError(t, err)
+	res := requireEvent(pb.WatchResponse_CREATED, created.Id)
+	requireSameLaptop(t, created, res.GetLaptop())
+
+	expensive := proto.Clone(created).(*pb.Laptop)
+	expensive.PriceUsd = 5000
+	err = laptopStore.Save(&pb.Laptop{Id: "not-matching", PriceUsd: 5000})
+	require.NoError(t, err)
+	err = laptopStore.Update(expensive, created.GetUpdatedAt())
+	require.NoError(t, err)
+	requireEvent(pb.WatchResponse_DELETED, created.Id)
+
+	cheap := proto.Clone(expensive).(*pb.Laptop)
+	cheap.PriceUsd = 2000
+	err = laptopStore.Update(cheap, expensive.GetUpdatedAt())
+	require.NoError(t, err)
+	requireEvent(pb.WatchResponse_CREATED, created.Id)
+
+	current.PriceUsd = 1000
+	err = laptopStore.Update(current, current.GetUpdatedAt())
+	require.NoError(t, err)
+	res = requireEvent(pb.WatchResponse_UPDATED, current.Id)
+	require.Equal(t, float64(1000), res.GetLaptop().GetPriceUsd())
+
+	err = laptopStore.Delete(current.Id)
+	require.NoError(t, err)
+	res = requireEvent(pb.WatchResponse_DELETED, current.Id)
+	require.Nil(t, res.GetLaptop())
+}
+
+func TestClientWatchLaptopsWithoutFilter(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	current := sample.NewLaptop()
+	err := laptopStore.Save(current)
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := laptopClient.WatchLaptops(ctx, &pb.WatchRequest{})
+	require.NoError(t, err)
+
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, pb.WatchResponse_CURRENT, res.GetEvent())
+	require.Equal(t, current.Id, res.GetLaptopId())
+
+	created := sample.NewLaptop()
+	err = laptopStore.Save(created)
+	require.NoError(t, err)
+
+	res, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, pb.WatchResponse_CREATED, res.GetEvent())
+	require.Equal(t, created.Id, res.GetLaptopId())
+}
+
+func TestClientCreateLaptops(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	existing := sample.NewLaptop()
+	err := laptopStore.Save(existing)
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	noID := sample.NewLaptop()
+	noID.Id = ""
+	invalidID := sample.NewLaptop()
+	invalidID.Id = "invalid-uuid"
+	laptops := []*pb.Laptop{sample.NewLaptop(), existing, invalidID, noID}
+
+	stream, err := laptopClient.CreateLaptops(context.Background())
+	require.NoError(t, err)
+	for _, laptop := range laptops {
+		err := stream.Send(&pb.CreateLaptopRequest{Laptop: laptop})
+		require.NoError(t, err)
+	}
+	res, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+
+	require.Equal(t, uint32(2), res.GetCreatedCount())
+	require.Equal(t, uint32(2), res.GetFailedCount())
+	require.Len(t, res.GetResults(), 4)
+
+	require.Equal(t, laptops[0].Id, res.GetResults()[0].GetId())
+	require.Equal(t, code.Code_ALREADY_EXISTS, res.GetResults()[1].GetCode())
+	require.Equal(t, code.Code_INVALID_ARGUMENT, res.GetResults()[2].GetCode())
+	require.NotEmpty(t, res.GetResults()[2].GetMessage())
+
+	other, err := laptopStore.Find(res.GetResults()[3].GetId())
+	require.NoError(t, err)
+	require.NotNil(t, other)
+}
+
+func TestLaptopClientCreateLaptopsInvalid(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	conn, err := grpc.Dial(serverAddress, grpc.WithInsecure())
+	require.NoError(t, err)
+	laptopClient := client.NewLaptopClient(conn)
+
+	invalid := sample.NewLaptop()
+	invalid.PriceUsd = -1
+	laptops := []*pb.Laptop{sample.NewLaptop(), invalid, sample.NewLaptop()}
+
+	res, err := laptopClient.CreateLaptops(laptops)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), res.GetCreatedCount())
+	require.Equal(t, uint32(1), res.GetFailedCount())
+	require.Len(t, res.GetResults(), 3)
+	require.Equal(t, laptops[0].GetId(), res.GetResults()[0].GetId())
+	require.Equal(t, code.Code_INVALID_ARGUMENT, res.GetResults()[1].GetCode())
+	require.Equal(t, laptops[2].GetId(), res.GetResults()[2].GetId())
+
+	found, err := laptopStore.Find(invalid.GetId())
+	require.NoError(t, err)
+	require.Nil(t, found)
+
+	res, err = laptopClient.CreateLaptops([]*pb.Laptop{invalid})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), res.GetFailedCount())
+}
+
+func TestClientCatalogExportImport(t *testing.T) {
+	t.Parallel()
+
+	sourceLaptopStore := NewInMemoryLaptopStore()
+	sourceRatingStore := NewInMemoryRatingStore()
+	sourceImageStore, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+
+	laptop := sample.NewLaptop()
+	err = sourceLaptopStore.Save(laptop)
+	require.NoError(t, err)
+	_, err = sourceRatingStore.Add(laptop.Id, 8)
+	require.NoError(t, err)
+	imageData := sample.NewPNG(1, 1)
+	imageID, err := sourceImageStore.Save(laptop.Id, *bytes.NewBuffer(imageData))
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, sourceLaptopStore, sourceImageStore, sourceRatingStore)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	stream, err := laptopClient.ExportCatalog(context.Background(), &pb.ExportCatalogRequest{})
+	require.NoError(t, err)
+	var records []*pb.CatalogRecord
+	for {
+		record, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+	require.Len(t, records, 3)
+	require.Equal(t, laptop.Id, records[0].GetLaptop().GetId())
+	require.Equal(t, uint32(1), records[1].GetRating().GetCount())
+	require.Equal(t, imageID, records[2].GetImage().GetId())
+	require.Equal(t, imageData, records[2].GetImage().GetData())
+
+	targetLaptopStore := NewInMemoryLaptopStore()
+	targetRatingStore := NewInMemoryRatingStore()
+	targetImageStore, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	serverAddress = startTestLaptopServer(t, targetLaptopStore, targetImageStore, targetRatingStore)
+	laptopClient = newTestLaptopClient(t, serverAddress)
+
+	importCatalog := func(options *pb.ImportCatalogOptions) (*pb.ImportCatalogResponse, error) {
+		stream, err := laptopClient.ImportCatalog(context.Background())
+		require.NoError(t, err)
+		err = stream.Send(&pb.ImportCatalogRequest{Data: &pb.ImportCatalogRequest_Options{Options: options}})
+		require.NoError(t, err)
+		for _, record := range records {
+			err := stream.Send(&pb.ImportCatalogRequest{Data: &pb.ImportCatalogRequest_Record{Record: record}})
+			if err != nil {
+				break
+			}
+		}
+		return stream.CloseAndRecv()
+	}
+
+	res, err := importCatalog(&pb.ImportCatalogOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), res.GetCreated())
+	other, err := targetLaptopStore.Find(laptop.Id)
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	res, err = importCatalog(&pb.ImportCatalogOptions{})
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), res.GetCreated())
+
+	other, err = targetLaptopStore.Find(laptop.Id)
+	require.NoError(t, err)
+	requireSameLaptop(t, laptop, other)
+	rating, err := targetRatingStore.Find(laptop.Id)
+	require.NoError(t, err)
+	require.Equal(t, &Rating{Count: 1, Sum: 8}, rating)
+	info, err := targetImageStore.Find(imageID)
+	require.NoError(t, err)
+	data, err := os.ReadFile(info.Path)
+	require.NoError(t, err)
+	require.Equal(t, imageData, data)
+
+	res, err = importCatalog(&pb.ImportCatalogOptions{})
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), res.GetSkipped())
+
+	res, err = importCatalog(&pb.ImportCatalogOptions{OnConflict: pb.ImportCatalogOptions_OVERWRITE})
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), res.GetOverwritten())
+
+	_, err = importCatalog(&pb.ImportCatalogOptions{OnConflict: pb.ImportCatalogOptions_FAIL})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"example.com/pcbook/pb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *LaptopServer) ListLaptopImages(ctx context.Context, req *pb.ListLaptopImagesRequest) (*pb.ListLaptopImagesResponse, error) {
+	laptopID := req.GetLaptopId()
+	_, err := uuid.Parse(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "laptop Id is not a valid UUID: %v", err)
+	}
+
+	laptop, err := server.laptopStore.Find(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+	}
+	if laptop == nil {
+		return nil, status.Errorf(codes.NotFound, "laptop %s is not found", laptopID)
+	}
+
+	images, err := server.laptopImages(laptopID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListLaptopImagesResponse{Images: images}, nil
+}
+
+func (server *LaptopServer) DeleteImage(ctx context.Context, req *pb.DeleteImageRequest) (*pb.DeleteImageResponse, error) {
+	log.Printf("receive a delete image request with id: %s", req.GetImageId())
+
+	err := server.imageStore.Delete(req.GetImageId())
+	if err != nil {
+		return nil, imageErr("cannot delete image", err)
+	}
+
+	log.Printf("deleted image with id: %s", req.GetImageId())
+	return &pb.DeleteImageResponse{ImageId: req.GetImageId()}, nil
+}
+
+func (server *LaptopServer) SetPrimaryImage(ctx context.Context, req *pb.SetPrimaryImageRequest) (*pb.SetPrimaryImageResponse, error) {
+	log.Printf("receive a set primary image request with id: %s", req.GetImageId())
+
+	info, err := server.imageStore.Find(req.GetImageId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find image: %v", err)
+	}
+	if info == nil {
+		return nil, status.Errorf(codes.NotFound, "image %s is not found", req.GetImageId())
+	}
+
+	err = server.imageStore.SetPrimary(req.GetImageId())
+	if err != nil {
+		return nil, imageErr("cannot set primary image", err)
+	}
+
+	images, err := server.laptopImages(info.LaptopID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SetPrimaryImageResponse{Images: images}, nil
+}
+
+func (server *LaptopServer) ReorderImages(ctx context.Context, req *pb.ReorderImagesRequest) (*pb.ReorderImagesResponse, error) {
+	log.Printf("receive a reorder images request for laptop %s: %v", req.GetLaptopId(), req.GetImageIds())
+
+	err := server.imageStore.Reorder(req.GetLaptopId(), req.GetImageIds())
+	if err != nil {
+		return nil, imageErr("cannot reorder images", err)
+	}
+
+	images, err := server.laptopImages(req.GetLaptopId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ReorderImagesResponse{Images: images}, nil
+}
+
+func (server *LaptopServer) laptopImages(laptopID string) ([]*pb.LaptopImage, error) {
+	found, err := server.imageStore.List(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot list images: %v", err)
+	}
+
+	images := make([]*pb.LaptopImage, 0, len(found))
+	for i, info := range found {
+		image := &pb.LaptopImage{
+			Id:        info.ID,
+			ImageType: info.Type,
+			Primary:   i == 0,
+			MimeType:  info.MIMEType,
+			Width:     uint32(info.Width),
+			Height:    uint32(info.Height),
+		}
+		for _, thumbnail := range info.Thumbnails {
+			image.ThumbnailSizes = append(image.ThumbnailSizes, uint32(thumbnail.LongEdge))
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// primaryImageID returns the id of the first image of the laptop, or an empty string if it has none
+func (server *LaptopServer) primaryImageID(laptopID string) (string, error) {
+	if server.imageStore == nil {
+		return "", nil
+	}
+	images, err := server.imageStore.List(laptopID)
+	if err != nil || len(images) == 0 {
+		return "", err
+	}
+	return images[0].ID, nil
+}
+
+// deleteLaptopImages removes the images of a deleted laptop. It keeps going after a failure,
+// so that as few files as possible are left behind.
+func (server *LaptopServer) deleteLaptopImages(laptopID string) {
+	if server.imageStore == nil {
+		return
+	}
+	images, err := server.imageStore.List(laptopID)
+	if err != nil {
+		log.Printf("cannot list the images of laptop %s: %v", laptopID, err)
+		return
+	}
+	for _, info := range images {
+		err := server.imageStore.Delete(info.ID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			log.Printf("cannot delete image %s of laptop %s: %v", info.ID, laptopID, err)
+		}
+	}
+}
+
+func imageErr(msg string, err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, ErrImageOrder):
+		code = codes.InvalidArgument
+	}
+	return logError(status.Errorf(code, "%s: %v", msg, err))
+}
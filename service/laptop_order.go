@@ -0,0 +1,101 @@
+package service
+
+import (
+	"container/heap"
+	"sort"
+
+	"example.com/pcbook/memsize"
+	"example.com/pcbook/pb"
+)
+
+type sortedLaptop struct {
+	laptop *pb.Laptop
+	value  float64
+}
+
+// laptopOrder sorts laptops by the value of the OrderBy field, ties are broken by ascending id
+// so that every laptop has a stable position to resume a search from. Ordering by id compares the ids.
+type laptopOrder struct {
+	orderBy     *pb.OrderBy
+	ratingStore RatingStore
+	scores      map[string]float64
+}
+
+func (order laptopOrder) value(laptop *pb.Laptop) (float64, error) {
+	switch order.orderBy.GetField() {
+	case pb.OrderBy_PRICE:
+		return laptop.GetPriceUsd(), nil
+	case pb.OrderBy_RELEASE_YEAR:
+		return float64(laptop.GetReleaseYear()), nil
+	case pb.OrderBy_CPU_CORES:
+		return float64(laptop.GetCpu().GetNumberCores()), nil
+	case pb.OrderBy_CPU_GHZ:
+		return laptop.GetCpu().GetMinGhz(), nil
+	case pb.OrderBy_RAM:
+		return memsize.InUnit(laptop.GetRam(), pb.Memory_BIT), nil
+	case pb.OrderBy_RATING:
+		if order.ratingStore == nil {
+			return 0, nil
+		}
+		rating, err := order.ratingStore.Find(laptop.GetId())
+		if err != nil || rating == nil || rating.Count == 0 {
+			return 0, err
+		}
+		return rating.Sum / float64(rating.Count), nil
+	case pb.OrderBy_RELEVANCE:
+		return order.scores[laptop.GetId()], nil
+	default:
+		return 0, nil
+	}
+}
+
+// less reports whether a comes before b
+func (order laptopOrder) less(a, b sortedLaptop) bool {
+	if a.value != b.value {
+		if order.orderBy.GetDescending() {
+			return a.value > b.value
+		}
+		return a.value < b.value
+	}
+	// every laptop has the same value when ordering by id, so the id decides in both directions
+	if order.orderBy.GetField() == pb.OrderBy_ID && order.orderBy.GetDescending() {
+		return a.laptop.GetId() > b.laptop.GetId()
+	}
+	return a.laptop.GetId() < b.laptop.GetId()
+}
+
+// topLaptops keeps the first n laptops added to it, or all of them when n <= 0.
+// It is a heap with the last kept laptop at the root, so a laptop that comes after it can be dropped right away.
+type topLaptops struct {
+	order laptopOrder
+	n     int
+	items []sortedLaptop
+}
+
+func (top *topLaptops) Len() int           { return len(top.items) }
+func (top *topLaptops) Less(i, j int) bool { return top.order.less(top.items[j], top.items[i]) }
+func (top *topLaptops) Swap(i, j int)      { top.items[i], top.items[j] = top.items[j], top.items[i] }
+func (top *topLaptops) Push(x interface{}) { top.items = append(top.items, x.(sortedLaptop)) }
+func (top *topLaptops) Pop() interface{} {
+	last := top.items[len(top.items)-1]
+	top.items = top.items[:len(top.items)-1]
+	return last
+}
+
+func (top *topLaptops) add(item sortedLaptop) {
+	if top.n <= 0 || len(top.items) < top.n {
+		heap.Push(top, item)
+		return
+	}
+	if top.order.less(item, top.items[0]) {
+		top.items[0] = item
+		heap.Fix(top, 0)
+	}
+}
+
+func (top *topLaptops) sorted() []sortedLaptop {
+	sort.Slice(top.items, func(i, j int) bool {
+		return top.order.less(top.items[i], top.items[j])
+	})
+	return top.items
+}
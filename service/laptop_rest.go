@@ -0,0 +1,125 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"example.com/pcbook/pb"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const maxCreateLaptopsBodySize = 64 << 20
+
+// CreateLaptopsHandler serves CreateLaptops over REST, which the generated gateway cannot do in-process
+// since it is a client-streaming call. The body is a JSON array of laptops.
+func (server *LaptopServer) CreateLaptopsHandler(mux *runtime.ServeMux) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
+		ctx := req.Context()
+		_, outboundMarshaler := runtime.MarshalerForRequest(mux, req)
+
+		res, err := server.createLaptopsFromJSON(req)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, req, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", outboundMarshaler.ContentType(res))
+		data, err := outboundMarshaler.Marshal(res)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, req, status.Errorf(codes.Internal, "cannot marshal response: %v", err))
+			return
+		}
+		_, err = w.Write(data)
+		if err != nil {
+			log.Printf("cannot write response: %v", err)
+		}
+	}
+}
+
+func (server *LaptopServer) createLaptopsFromJSON(req *http.Request) (*pb.CreateLaptopsResponse, error) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, maxCreateLaptopsBodySize))
+	var items []json.RawMessage
+	err := decoder.Decode(&items)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "body must be a JSON array of laptops: %v", err)
+	}
+
+	res := &pb.CreateLaptopsResponse{}
+	for i, item := range items {
+		laptop := &pb.Laptop{}
+		err := protojson.Unmarshal(item, laptop)
+		if err != nil {
+			res.Results = append(res.Results, &pb.CreateLaptopsResult{
+				Code:    code.Code_INVALID_ARGUMENT,
+				Message: fmt.Sprintf("laptop %d is not valid: %v", i, err),
+			})
+			continue
+		}
+
+		result, err := server.createLaptopsItem(req.Context(), laptop)
+		if err != nil {
+			return nil, err
+		}
+		res.Results = append(res.Results, result)
+	}
+
+	countCreateLaptopsResults(res)
+	log.Printf("created %d laptops, %d failed", res.GetCreatedCount(), res.GetFailedCount())
+	return res, nil
+}
+
+// DownloadImageHandler serves the raw image instead of the JSON messages of the generated handler,
+// so that it can be used as the source of an <img>. Range requests are supported, and the
+// thumbnail_size query parameter picks a thumbnail like in DownloadImageRequest.
+func (server *LaptopServer) DownloadImageHandler(mux *runtime.ServeMux) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
+		imageID := pathParams["image_id"]
+		log.Printf("receive a REST download image request with id: %s", imageID)
+
+		thumbnailSize := 0
+		if value := req.URL.Query().Get("thumbnail_size"); value != "" {
+			size, err := strconv.ParseUint(value, 10, 31)
+			if err != nil {
+				_, outboundMarshaler := runtime.MarshalerForRequest(mux, req)
+				runtime.HTTPError(req.Context(), mux, outboundMarshaler, w, req, status.Errorf(codes.InvalidArgument, "invalid thumbnail size: %v", err))
+				return
+			}
+			thumbnailSize = int(size)
+		}
+
+		info, file, err := server.openImage(imageID, thumbnailSize)
+		if err != nil {
+			_, outboundMarshaler := runtime.MarshalerForRequest(mux, req)
+			runtime.HTTPError(req.Context(), mux, outboundMarshaler, w, req, logError(err))
+			return
+		}
+		defer file.Close()
+
+		stat, err := file.Stat()
+		if err != nil {
+			_, outboundMarshaler := runtime.MarshalerForRequest(mux, req)
+			runtime.HTTPError(req.Context(), mux, outboundMarshaler, w, req, logError(status.Errorf(codes.Internal, "cannot stat image: %v", err)))
+			return
+		}
+
+		// images saved before their type was detected only have an extension
+		contentType := info.GetMimeType()
+		if contentType == "" {
+			contentType = mime.TypeByExtension(info.GetImageType())
+		}
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		http.ServeContent(w, req, "", stat.ModTime(), file)
+	}
+}
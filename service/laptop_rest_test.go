@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"example.com/pcbook/serializer"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/test-go/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestRestCreateLaptops(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	server := NewLaptopServer(laptopStore, nil, nil)
+
+	mux := runtime.NewServeMux()
+	err := mux.HandlePath("POST", "/v1/laptop/create_batch", server.CreateLaptopsHandler(mux))
+	require.NoError(t, err)
+
+	laptop := sample.NewLaptop()
+	laptopJSON, err := serializer.ProtobuffToJSON(laptop)
+	require.NoError(t, err)
+
+	body := "[" + string(laptopJSON) + "," + string(laptopJSON) + `, {"price_usd": "not a number"}]`
+	req := httptest.NewRequest(http.MethodPost, "/v1/laptop/create_batch", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	res := &pb.CreateLaptopsResponse{}
+	err = protojson.Unmarshal(recorder.Body.Bytes(), res)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), res.GetCreatedCount())
+	require.Equal(t, uint32(2), res.GetFailedCount())
+	require.Equal(t, laptop.Id, res.GetResults()[0].GetId())
+	require.Equal(t, code.Code_ALREADY_EXISTS, res.GetResults()[1].GetCode())
+	require.Equal(t, code.Code_INVALID_ARGUMENT, res.GetResults()[2].GetCode())
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/laptop/create_batch", strings.NewReader(`{"id": "not an array"}`))
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRestDownloadImage(t *testing.T) {
+	t.Parallel()
+
+	imageStore, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	server := NewLaptopServer(NewInMemoryLaptopStore(), imageStore, nil)
+
+	mux := runtime.NewServeMux()
+	err = mux.HandlePath("GET", "/v1/laptop/images/{image_id}", server.DownloadImageHandler(mux))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile("../tmp/laptop.jpg")
+	require.NoError(t, err)
+	imageID, err := imageStore.Save(sample.NewLaptop().GetId(), *bytes.NewBuffer(data))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		imageID     string
+		rangeHeader string
+		code        int
+		body        string
+	}{
+		{
+			name:    "full",
+			imageID: imageID,
+			code:    http.StatusOK,
+			body:    string(data),
+		},
+		{
+			name:        "range",
+			imageID:     imageID,
+			rangeHeader: "bytes=4-7",
+			code:        http.StatusPartialContent,
+			body:        string(data[4:8]),
+		},
+		{
+			name:        "suffix_range",
+			imageID:     imageID,
+			rangeHeader: "bytes=-3",
+			code:        http.StatusPartialContent,
+			body:        string(data[len(data)-3:]),
+		},
+		{
+			name:        "unsatisfiable_range",
+			imageID:     imageID,
+			rangeHeader: fmt.Sprintf("bytes=%d-", len(data)),
+			code:        http.StatusRequestedRangeNotSatisfiable,
+		},
+		{
+			name:    "not_found",
+			imageID: "unknown",
+			code:    http.StatusNotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/laptop/images/"+tc.imageID, nil)
+			if tc.rangeHeader != "" {
+				req.Header.Set("Range", tc.rangeHeader)
+			}
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, req)
+			require.Equal(t, tc.code, recorder.Code)
+
+			if tc.body != "" {
+				require.Equal(t, "image/jpeg", recorder.Header().Get("Content-Type"))
+				body, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				require.Equal(t, tc.body, string(body))
+			}
+		})
+	}
+}
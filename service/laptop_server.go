@@ -6,25 +6,59 @@ import (
 	"errors"
 	"io"
 	"log"
+	"os"
 
+	"example.com/pcbook/imageformat"
 	"example.com/pcbook/pb"
+	"example.com/pcbook/query"
+	"example.com/pcbook/validate"
 	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/code"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-const maxImageSize = 1 << 20
+const (
+	maxImageSize   = 1 << 20
+	imageChunkSize = 64 << 10
+)
 
 type LaptopServer struct {
 	pb.UnimplementedLaptopServiceServer
 	laptopStore LaptopStore
 	imageStore  ImageStore
 	ratingStore RatingStore
+	stockStore  StockStore
+	idempotency *IdempotencyCache
+	thumbnailer *Thumbnailer
 }
 
 func NewLaptopServer(laptopStore LaptopStore, imageStore ImageStore, ratingStore RatingStore) *LaptopServer {
-	return &LaptopServer{laptopStore: laptopStore, imageStore: imageStore, ratingStore: ratingStore}
+	return &LaptopServer{
+		laptopStore: laptopStore,
+		imageStore:  imageStore,
+		ratingStore: ratingStore,
+		idempotency: NewIdempotencyCache(DefaultIdempotencyWindow),
+	}
+}
+
+// SetStockStore lets searches keep the laptops in stock only
+func (server *LaptopServer) SetStockStore(stockStore StockStore) {
+	server.stockStore = stockStore
+}
+
+// SetThumbnailer makes the thumbnails of uploaded images with thumbnailer
+func (server *LaptopServer) SetThumbnailer(thumbnailer *Thumbnailer) {
+	server.thumbnailer = thumbnailer
+}
+
+// SetIdempotencyCache replaces the cache of idempotency keys, nil ignores the keys
+func (server *LaptopServer) SetIdempotencyCache(cache *IdempotencyCache) {
+	server.idempotency = cache
 }
+
 func (server *LaptopServer) CreateLaptop(
 	ctx context.Context,
 	req *pb.CreateLaptopRequest,
@@ -32,59 +66,548 @@ func (server *LaptopServer) CreateLaptop(
 	laptop := req.GetLaptop()
 	log.Printf("receive a create laptop request with id: %s", laptop.Id)
 
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot marshal request: %v", err)
+	}
+
+	res, err := server.idempotency.do(ctx, "CreateLaptop", fingerprint(data), func() (proto.Message, error) {
+		err := server.createLaptop(ctx, laptop)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.CreateLaptopResponse{Id: laptop.Id}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*pb.CreateLaptopResponse), nil
+
+}
+
+// CreateLaptops saves every laptop of the stream, a laptop that cannot be saved doesn't stop the others
+func (server *LaptopServer) CreateLaptops(stream pb.LaptopService_CreateLaptopsServer) error {
+	res := &pb.CreateLaptopsResponse{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return logError(status.Errorf(codes.Unknown, "cannot receive stream request: %v", err))
+		}
+
+		result, err := server.createLaptopsItem(stream.Context(), req.GetLaptop())
+		if err != nil {
+			return err
+		}
+		res.Results = append(res.Results, result)
+	}
+
+	countCreateLaptopsResults(res)
+	log.Printf("created %d laptops, %d failed", res.GetCreatedCount(), res.GetFailedCount())
+	err := stream.SendAndClose(res)
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send response: %v", err))
+	}
+	return nil
+}
+
+// createLaptopsItem returns the outcome of creating one laptop of a batch,
+// or an error when the whole batch has to stop because the request is canceled
+func (server *LaptopServer) createLaptopsItem(ctx context.Context, laptop *pb.Laptop) (*pb.CreateLaptopsResult, error) {
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+	if laptop == nil {
+		return &pb.CreateLaptopsResult{Code: code.Code_INVALID_ARGUMENT, Message: "laptop is missing"}, nil
+	}
+
+	err := server.createLaptop(ctx, laptop)
+	if err != nil {
+		st := status.Convert(err)
+		if st.Code() == codes.Canceled || st.Code() == codes.DeadlineExceeded {
+			return nil, err
+		}
+		return &pb.CreateLaptopsResult{Code: code.Code(st.Code()), Message: st.Message()}, nil
+	}
+	return &pb.CreateLaptopsResult{Id: laptop.Id}, nil
+}
+
+func countCreateLaptopsResults(res *pb.CreateLaptopsResponse) {
+	for _, result := range res.GetResults() {
+		if result.GetCode() == code.Code_OK {
+			res.CreatedCount++
+		} else {
+			res.FailedCount++
+		}
+	}
+}
+
+// createLaptop assigns an id to the laptop when it has none and saves it
+func (server *LaptopServer) createLaptop(ctx context.Context, laptop *pb.Laptop) error {
 	if len(laptop.Id) > 0 {
 		_, err := uuid.Parse(laptop.Id)
 		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "laptop Id is not a valid UUID: %v", err)
+			return status.Errorf(codes.InvalidArgument, "laptop Id is not a valid UUID: %v", err)
 		}
 	} else {
 		id, err := uuid.NewRandom()
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "cannot generate a new laptop ID: %v", err)
+			return status.Errorf(codes.Internal, "cannot generate a new laptop ID: %v", err)
 		}
 		laptop.Id = id.String()
 	}
 
+	err := validate.Laptop(laptop)
+	if err != nil {
+		return err
+	}
+
+	// updated_at is the version UpdateLaptop checks, so it is always set by the server
+	laptop.UpdatedAt = timestamppb.Now()
+
 	if err := contextErr(ctx); err != nil {
-		return nil, err
+		return err
 	}
 
-	err := server.laptopStore.Save(laptop)
+	err = server.laptopStore.Save(laptop)
 	if err != nil {
 		code := codes.Internal
 		if errors.Is(err, ErrAlreadyExists) {
 			code = codes.AlreadyExists
 		}
-		return nil, status.Errorf(code, "cannot save laptop to the server: %v", err)
+		return status.Errorf(code, "cannot save laptop to the server: %v", err)
 	}
 
 	log.Printf("saved laptop with id: %s", laptop.Id)
-	res := &pb.CreateLaptopResponse{
-		Id: laptop.Id,
+	return nil
+}
+
+func (server *LaptopServer) GetLaptop(
+	ctx context.Context,
+	req *pb.GetLaptopRequest,
+) (*pb.GetLaptopResponse, error) {
+	laptopID := req.GetId()
+	log.Printf("receive a get laptop request with id: %s", laptopID)
+
+	_, err := uuid.Parse(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "laptop Id is not a valid UUID: %v", err)
+	}
+
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+
+	laptop, err := server.laptopStore.Find(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+	}
+	if laptop == nil {
+		return nil, status.Errorf(codes.NotFound, "laptop %s is not found", laptopID)
+	}
+
+	res := &pb.GetLaptopResponse{
+		Laptop: laptop,
 	}
 	return res, nil
+}
+
+func (server *LaptopServer) UpdateLaptop(
+	ctx context.Context,
+	req *pb.UpdateLaptopRequest,
+) (*pb.UpdateLaptopResponse, error) {
+	laptop := req.GetLaptop()
+	log.Printf("receive an update laptop request with id: %s", laptop.GetId())
+
+	_, err := uuid.Parse(laptop.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "laptop Id is not a valid UUID: %v", err)
+	}
+
+	lastUpdatedAt := laptop.GetUpdatedAt()
+	if lastUpdatedAt == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "updated_at of the laptop being modified must be provided")
+	}
+
+	err = validate.Laptop(laptop)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+
+	laptop.UpdatedAt = timestamppb.Now()
+
+	err = server.laptopStore.Update(laptop, lastUpdatedAt)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, ErrNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, ErrConflict) {
+			code = codes.Aborted
+		}
+		return nil, status.Errorf(code, "cannot update laptop: %v", err)
+	}
+
+	log.Printf("updated laptop with id: %s", laptop.Id)
+	res := &pb.UpdateLaptopResponse{
+		Laptop: laptop,
+	}
+	return res, nil
+}
+
+func (server *LaptopServer) DeleteLaptop(
+	ctx context.Context,
+	req *pb.DeleteLaptopRequest,
+) (*pb.DeleteLaptopResponse, error) {
+	laptopID := req.GetId()
+	log.Printf("receive a delete laptop request with id: %s", laptopID)
+
+	_, err := uuid.Parse(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "laptop Id is not a valid UUID: %v", err)
+	}
+
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+
+	err = server.laptopStore.Delete(laptopID)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, ErrNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Errorf(code, "cannot delete laptop: %v", err)
+	}
 
+	server.deleteLaptopImages(laptopID)
+
+	log.Printf("deleted laptop with id: %s", laptopID)
+	res := &pb.DeleteLaptopResponse{
+		Id: laptopID,
+	}
+	return res, nil
 }
 
 func (server *LaptopServer) SearchLaptop(req *pb.SearchLaptopRequest, stream pb.LaptopService_SearchLaptopServer) error {
 	filter := req.GetFilter()
-	log.Printf("receive a search laptop request with filter : %v", filter)
+	orderBy := req.GetOrderBy()
+	pageSize := int(req.GetPageSize())
+	log.Printf("receive a search laptop request with filter : %v, query: %q, order by: %v, page size: %d", filter, req.GetQuery(), orderBy, pageSize)
 
-	err := server.laptopStore.Search(stream.Context(), filter, func(laptop *pb.Laptop) error {
-		res := &pb.SearchLaptopResponse{Laptop: laptop}
-		err := stream.Send(res)
+	if len(req.GetQuery()) > 0 {
+		if filter != nil {
+			return status.Errorf(codes.InvalidArgument, "filter and query cannot be used together")
+		}
+		var err error
+		filter, err = query.Parse(req.GetQuery())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid query: %v", err)
+		}
+	}
+
+	if len(req.GetText()) > 0 && orderBy == nil {
+		orderBy = &pb.OrderBy{Field: pb.OrderBy_RELEVANCE, Descending: true}
+	}
+
+	if filter.GetInStockOnly() && server.stockStore == nil {
+		return status.Errorf(codes.FailedPrecondition, "the server has no inventory to filter by stock")
+	}
+
+	pageToken, err := decodePageToken(req.GetPageToken(), orderBy)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid page token: %v", err)
+	}
+
+	// scores stays nil when there is no text to match, so that every laptop is kept
+	var scores map[string]float64
+	if len(req.GetText()) > 0 {
+		scores, err = server.matchText(stream.Context(), filter, req.GetText())
+		if err != nil {
+			return status.Errorf(codes.Internal, "cannot match text: %v", err)
+		}
+	}
+
+	// keep tells whether a laptop that passes the filter is part of the result
+	keep := func(laptop *pb.Laptop) (bool, error) {
+		if scores != nil && scores[laptop.GetId()] == 0 {
+			return false, nil
+		}
+		return server.inStock(filter, laptop)
+	}
+
+	send := func(laptop *pb.Laptop, nextPageToken string) error {
+		primaryImageID, err := server.primaryImageID(laptop.GetId())
+		if err != nil {
+			return status.Errorf(codes.Internal, "cannot find primary image: %v", err)
+		}
+		res := &pb.SearchLaptopResponse{Laptop: laptop, NextPageToken: nextPageToken, PrimaryImageId: primaryImageID}
+		err = stream.Send(res)
 		if err != nil {
 			return err
 		}
 		log.Printf("sent laptop with id: %s", laptop.GetId())
 		return nil
+	}
+
+	// hold back the last laptop of the page until we know whether another page follows,
+	// so that the next page token can be sent along with it
+	var last *sortedLaptop
+	count := 0
+	nextPageToken := ""
+	emit := func(item sortedLaptop) error {
+		if pageSize > 0 && count == pageSize {
+			token, err := encodePageToken(*last, orderBy)
+			if err != nil {
+				return err
+			}
+			nextPageToken = token
+			return errPageComplete
+		}
+		if last != nil {
+			err := send(last.laptop, "")
+			if err != nil {
+				return err
+			}
+		}
+		last = &item
+		count++
+		return nil
+	}
+
+	if orderBy.GetField() == pb.OrderBy_ID && !orderBy.GetDescending() {
+		// stores return laptops in this order already, so they are streamed as soon as they are found
+		err = server.laptopStore.Search(stream.Context(), filter, pageToken.GetLastId(), func(laptop *pb.Laptop) error {
+			ok, err := keep(laptop)
+			if err != nil || !ok {
+				return err
+			}
+			return emit(sortedLaptop{laptop: laptop})
+		})
+	} else {
+		order := laptopOrder{orderBy: orderBy, ratingStore: server.ratingStore, scores: scores}
+		err = server.searchSorted(stream.Context(), filter, order, pageToken, pageSize, keep, emit)
+	}
+	if err != nil && !errors.Is(err, errPageComplete) {
+		return status.Errorf(codes.Internal, "unexpected error: %v", err)
+	}
+
+	if last != nil {
+		err = send(last.laptop, nextPageToken)
+		if err != nil {
+			return status.Errorf(codes.Internal, "unexpected error: %v", err)
+		}
+	}
+	return nil
+}
+
+// matchText scores the laptops that match text, using the store's index when it has one
+func (server *LaptopServer) matchText(ctx context.Context, filter *pb.Filter, text string) (map[string]float64, error) {
+	if matcher, ok := server.laptopStore.(TextMatcher); ok {
+		return matcher.MatchText(text)
+	}
+
+	// without an index, build one over the laptops that pass the filter
+	index := newTextIndex()
+	err := server.laptopStore.Search(ctx, filter, "", func(laptop *pb.Laptop) error {
+		index.add(laptop)
+		return nil
 	})
 	if err != nil {
-		return status.Errorf(codes.Internal, "unexpected error: %v", err)
+		return nil, err
+	}
+	return index.match(text), nil
+}
+
+// inStock tells whether laptop passes the in_stock_only condition of filter
+func (server *LaptopServer) inStock(filter *pb.Filter, laptop *pb.Laptop) (bool, error) {
+	if !filter.GetInStockOnly() {
+		return true, nil
+	}
+	available, err := server.stockStore.Available(laptop.GetId())
+	return available > 0, err
+}
+
+// searchSorted passes the laptops that follow pageToken to emit in the given order.
+// Only the top pageSize+1 laptops are kept in memory while searching, the extra one tells whether a next page exists.
+func (server *LaptopServer) searchSorted(
+	ctx context.Context,
+	filter *pb.Filter,
+	order laptopOrder,
+	pageToken *pb.SearchPageToken,
+	pageSize int,
+	keep func(laptop *pb.Laptop) (bool, error),
+	emit func(item sortedLaptop) error,
+) error {
+	top := &topLaptops{order: order}
+	if pageSize > 0 {
+		top.n = pageSize + 1
+	}
+
+	var cursor *sortedLaptop
+	if pageToken != nil {
+		cursor = &sortedLaptop{
+			laptop: &pb.Laptop{Id: pageToken.GetLastId()},
+			value:  pageToken.GetLastValue(),
+		}
+	}
+
+	err := server.laptopStore.Search(ctx, filter, "", func(laptop *pb.Laptop) error {
+		ok, err := keep(laptop)
+		if err != nil || !ok {
+			return err
+		}
+		value, err := order.value(laptop)
+		if err != nil {
+			return err
+		}
+		item := sortedLaptop{laptop: laptop, value: value}
+		if cursor != nil && !order.less(*cursor, item) {
+			return nil
+		}
+		top.add(item)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range top.sorted() {
+		err := emit(item)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// AggregateLaptops counts and summarizes the laptops that match the filter
+func (server *LaptopServer) AggregateLaptops(
+	ctx context.Context,
+	req *pb.AggregateLaptopsRequest,
+) (*pb.AggregateLaptopsResponse, error) {
+	log.Printf("receive an aggregate laptops request with filter: %v, facets: %v", req.GetFilter(), req.GetFacets())
+
+	filter := req.GetFilter()
+	if filter.GetInStockOnly() && server.stockStore == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "the server has no inventory to filter by stock")
+	}
+
+	aggregator, err := newLaptopAggregator(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	err = server.laptopStore.Search(ctx, filter, "", func(laptop *pb.Laptop) error {
+		ok, err := server.inStock(filter, laptop)
+		if err != nil || !ok {
+			return err
+		}
+		return aggregator.add(laptop)
+	})
+	if err != nil {
+		if err := contextErr(ctx); err != nil {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "cannot search laptops: %v", err)
+	}
+	return aggregator.response(), nil
+}
+
+// WatchLaptops streams the laptops that currently match the filter, then every change that affects the result.
+// A laptop that stops matching after an update is reported as deleted, one that starts matching as created.
+func (server *LaptopServer) WatchLaptops(req *pb.WatchRequest, stream pb.LaptopService_WatchLaptopsServer) error {
+	filter := req.GetFilter()
+	log.Printf("receive a watch laptops request with filter: %v", filter)
+
+	// stock changes are not laptop events, so a watcher couldn't follow them
+	if filter.GetInStockOnly() {
+		return status.Errorf(codes.InvalidArgument, "laptops cannot be watched by stock")
+	}
+
+	// subscribe before searching, so that no change is missed in between
+	subscription := server.laptopStore.Subscribe()
+	defer subscription.Close()
+
+	send := func(event pb.WatchResponse_Event, id string, laptop *pb.Laptop) error {
+		err := stream.Send(&pb.WatchResponse{Event: event, LaptopId: id, Laptop: laptop})
+		if err != nil {
+			return status.Errorf(codes.Unknown, "cannot send response: %v", err)
+		}
+		return nil
+	}
+
+	// the stores hold their lock while Search calls back, so the laptops are sent once it returns,
+	// otherwise a slow watcher would block every write
+	var current []*pb.Laptop
+	err := server.laptopStore.Search(stream.Context(), filter, "", func(laptop *pb.Laptop) error {
+		current = append(current, laptop)
+		return nil
+	})
+	if err != nil {
+		if err := contextErr(stream.Context()); err != nil {
+			return err
+		}
+		return logError(status.Errorf(codes.Internal, "cannot search laptops: %v", err))
+	}
+
+	matching := make(map[string]bool, len(current))
+	for _, laptop := range current {
+		matching[laptop.GetId()] = true
+		err := send(pb.WatchResponse_CURRENT, laptop.GetId(), laptop)
+		if err != nil {
+			return logError(err)
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return contextErr(stream.Context())
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return logError(status.Error(codes.ResourceExhausted, "watcher fell behind, please watch again"))
+			}
+			err := sendWatchEvent(event, filter, matching, send)
+			if err != nil {
+				return logError(err)
+			}
+		}
+	}
+}
+
+func sendWatchEvent(
+	event LaptopEvent,
+	filter *pb.Filter,
+	matching map[string]bool,
+	send func(event pb.WatchResponse_Event, id string, laptop *pb.Laptop) error,
+) error {
+	wasMatching := matching[event.ID]
+	isMatching := event.Type != LaptopDeleted && isQualified(filter, event.Laptop)
+
+	switch {
+	case isMatching && wasMatching:
+		// the initial search may already have seen a laptop created while it ran
+		if event.Type == LaptopCreated {
+			return nil
+		}
+		return send(pb.WatchResponse_UPDATED, event.ID, event.Laptop)
+	case isMatching:
+		matching[event.ID] = true
+		return send(pb.WatchResponse_CREATED, event.ID, event.Laptop)
+	case wasMatching:
+		delete(matching, event.ID)
+		return send(pb.WatchResponse_DELETED, event.ID, nil)
+	default:
+		return nil
+	}
+}
+
 func (server *LaptopServer) UploadImage(stream pb.LaptopService_UploadImageServer) error {
 	req, err := stream.Recv()
 	if err != nil {
@@ -92,9 +615,7 @@ func (server *LaptopServer) UploadImage(stream pb.LaptopService_UploadImageServe
 	}
 
 	laptopId := req.GetInfo().GetLaptopId()
-	imageType := req.GetInfo().GetImageType()
-
-	log.Printf("receive an upload image request for laptop %s with image type %s", laptopId, imageType)
+	log.Printf("receive an upload image request for laptop %s", laptopId)
 
 	laptop, err := server.laptopStore.Find(laptopId)
 	if err != nil {
@@ -141,17 +662,31 @@ func (server *LaptopServer) UploadImage(stream pb.LaptopService_UploadImageServe
 		}
 	}
 
-	imageId, err := server.imageStore.Save(laptopId, imageType, imageData)
+	info, err := proto.MarshalOptions{Deterministic: true}.Marshal(req.GetInfo())
 	if err != nil {
-		return logError(status.Errorf(codes.Internal, "cannot save image to the store : %v", err))
+		return logError(status.Errorf(codes.Internal, "cannot marshal image info: %v", err))
 	}
 
-	res := &pb.UploadImageResponse{
-		Id:   imageId,
-		Size: uint32(imageSize),
+	// a retried upload with the same key gets the id of the first one instead of storing a second copy
+	res, err := server.idempotency.do(stream.Context(), "UploadImage", fingerprint(info, imageData.Bytes()), func() (proto.Message, error) {
+		imageId, err := server.imageStore.Save(laptopId, imageData)
+		if errors.Is(err, imageformat.ErrUnsupported) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid image: %v", err)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot save image to the store : %v", err)
+		}
+		server.makeThumbnails(imageId)
+		return &pb.UploadImageResponse{
+			Id:   imageId,
+			Size: uint32(imageSize),
+		}, nil
+	})
+	if err != nil {
+		return logError(err)
 	}
 
-	err = stream.SendAndClose(res)
+	err = stream.SendAndClose(res.(*pb.UploadImageResponse))
 	if err != nil {
 		return logError(status.Errorf(codes.Unknown, "cannot send response: %v", err))
 	}
@@ -159,6 +694,99 @@ func (server *LaptopServer) UploadImage(stream pb.LaptopService_UploadImageServe
 	return nil
 }
 
+func (server *LaptopServer) DownloadImage(req *pb.DownloadImageRequest, stream pb.LaptopService_DownloadImageServer) error {
+	log.Printf("receive a download image request with id: %s", req.GetImageId())
+
+	info, file, err := server.openImage(req.GetImageId(), int(req.GetThumbnailSize()))
+	if err != nil {
+		return logError(err)
+	}
+	defer file.Close()
+
+	res := &pb.DownloadImageResponse{
+		Data: &pb.DownloadImageResponse_Info{Info: info},
+	}
+	err = stream.Send(res)
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send image info: %v", err))
+	}
+
+	buffer := make([]byte, imageChunkSize)
+	for {
+		if err := contextErr(stream.Context()); err != nil {
+			return err
+		}
+
+		n, err := file.Read(buffer)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return logError(status.Errorf(codes.Internal, "cannot read image: %v", err))
+		}
+
+		res := &pb.DownloadImageResponse{
+			Data: &pb.DownloadImageResponse_ChunkData{ChunkData: buffer[:n]},
+		}
+		err = stream.Send(res)
+		if err != nil {
+			return logError(status.Errorf(codes.Unknown, "cannot send chunk data: %v", err))
+		}
+	}
+
+	log.Printf("sent image with id: %s, size %d", req.GetImageId(), info.GetSize())
+	return nil
+}
+
+// makeThumbnails queues the image for the thumbnailer, if there is one
+func (server *LaptopServer) makeThumbnails(imageID string) {
+	if server.thumbnailer != nil && !server.thumbnailer.Enqueue(imageID) {
+		log.Printf("thumbnail queue is full, image %s has no thumbnails", imageID)
+	}
+}
+
+// openImage returns the info of an image and its file, which the caller must close.
+// With a thumbnail size, they are those of the smallest thumbnail that is large enough, if there is one.
+func (server *LaptopServer) openImage(imageID string, thumbnailSize int) (*pb.ImageInfo, *os.File, error) {
+	found, err := server.imageStore.Find(imageID)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "cannot find image: %v", err)
+	}
+	if found == nil {
+		return nil, nil, status.Errorf(codes.NotFound, "image %s is not found", imageID)
+	}
+
+	path, width, height := found.Path, found.Width, found.Height
+	if thumbnailSize > 0 {
+		if thumbnail := found.Thumbnail(thumbnailSize); thumbnail != nil {
+			path, width, height = thumbnail.Path, thumbnail.Width, thumbnail.Height
+		}
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, status.Errorf(codes.NotFound, "image %s is not found", imageID)
+	}
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "cannot open image: %v", err)
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, status.Errorf(codes.Internal, "cannot stat image: %v", err)
+	}
+
+	info := &pb.ImageInfo{
+		LaptopId:  found.LaptopID,
+		ImageType: found.Type,
+		Size:      uint32(stat.Size()),
+		MimeType:  found.MIMEType,
+		Width:     uint32(width),
+		Height:    uint32(height),
+	}
+	return info, file, nil
+}
+
 func (server *LaptopServer) Ratelaptop(stream pb.LaptopService_RatelaptopServer) error {
 	for {
 		err := contextErr(stream.Context())
@@ -9,6 +9,7 @@ import (
 	"github.com/test-go/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestServerCreateLaptop(t *testing.T) {
@@ -21,6 +22,9 @@ func TestServerCreateLaptop(t *testing.T) {
 	laptopInvalidId := sample.NewLaptop()
 	laptopInvalidId.Id = "invalid-uuid"
 
+	laptopInvalidCPU := sample.NewLaptop()
+	laptopInvalidCPU.Cpu.MinGhz = laptopInvalidCPU.Cpu.MaxGhz + 1
+
 	laptopDuplicateID := sample.NewLaptop()
 	storeDuplicateID := NewInMemoryLaptopStore()
 	err := storeDuplicateID.Save(laptopDuplicateID)
@@ -50,6 +54,12 @@ func TestServerCreateLaptop(t *testing.T) {
 			store:  NewInMemoryLaptopStore(),
 			code:   codes.InvalidArgument,
 		},
+		{
+			name:   "failure_invalid_cpu",
+			laptop: laptopInvalidCPU,
+			store:  NewInMemoryLaptopStore(),
+			code:   codes.InvalidArgument,
+		},
 		{
 			name:   "failure_duplicate_id",
 			laptop: laptopDuplicateID,
@@ -87,3 +97,170 @@ func TestServerCreateLaptop(t *testing.T) {
 		})
 	}
 }
+
+func TestServerGetLaptop(t *testing.T) {
+	t.Parallel()
+
+	laptop := sample.NewLaptop()
+	store := NewInMemoryLaptopStore()
+	err := store.Save(laptop)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		id   string
+		code codes.Code
+	}{
+		{
+			name: "success",
+			id:   laptop.Id,
+			code: codes.OK,
+		},
+		{
+			name: "failure_invalid_id",
+			id:   "invalid-uuid",
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "failure_not_found",
+			id:   sample.NewLaptop().Id,
+			code: codes.NotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &pb.GetLaptopRequest{
+				Id: tc.id,
+			}
+			server := NewLaptopServer(store, nil, nil)
+
+			res, err := server.GetLaptop(context.Background(), req)
+			if tc.code == codes.OK {
+				require.NoError(t, err)
+				require.NotNil(t, res)
+				requireSameLaptop(t, laptop, res.GetLaptop())
+			} else {
+				require.Error(t, err)
+				require.Nil(t, res)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, tc.code, st.Code())
+			}
+		})
+	}
+}
+
+func TestServerUpdateLaptop(t *testing.T) {
+	t.Parallel()
+
+	store := NewInMemoryLaptopStore()
+	server := NewLaptopServer(store, nil, nil)
+
+	laptop := sample.NewLaptop()
+	err := store.Save(laptop)
+	require.NoError(t, err)
+
+	lastUpdatedAt := laptop.GetUpdatedAt().AsTime()
+
+	changed := proto.Clone(laptop).(*pb.Laptop)
+	changed.PriceUsd = 999
+	res, err := server.UpdateLaptop(context.Background(), &pb.UpdateLaptopRequest{Laptop: changed})
+	require.NoError(t, err)
+	require.Equal(t, float64(999), res.GetLaptop().GetPriceUsd())
+	require.True(t, res.GetLaptop().GetUpdatedAt().AsTime().After(lastUpdatedAt))
+
+	// the caller still holds the old updated_at, so a second update must be rejected
+	stale := proto.Clone(laptop).(*pb.Laptop)
+	_, err = server.UpdateLaptop(context.Background(), &pb.UpdateLaptopRequest{Laptop: stale})
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = server.UpdateLaptop(context.Background(), &pb.UpdateLaptopRequest{Laptop: sample.NewLaptop()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = server.DeleteLaptop(context.Background(), &pb.DeleteLaptopRequest{Id: laptop.Id})
+	require.NoError(t, err)
+
+	_, err = server.DeleteLaptop(context.Background(), &pb.DeleteLaptopRequest{Id: laptop.Id})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServerAggregateLaptops(t *testing.T) {
+	t.Parallel()
+
+	store := NewInMemoryLaptopStore()
+	server := NewLaptopServer(store, nil, nil)
+
+	newLaptop := func(brand string, price float64, ramGB uint64, panel pb.Screen_Panel) {
+		laptop := sample.NewLaptop()
+		laptop.Brand = brand
+		laptop.PriceUsd = price
+		laptop.Ram = &pb.Memory{Value: ramGB, Unit: pb.Memory_GIGABYTE}
+		laptop.Screen.Panel = panel
+		laptop.Weight = &pb.Laptop_WeightKg{WeightKg: 2}
+		err := store.Save(laptop)
+		require.NoError(t, err)
+	}
+	newLaptop("Dell", 1000, 8, pb.Screen_IPS)
+	newLaptop("Dell", 1500, 16, pb.Screen_OLED)
+	newLaptop("Apple", 2500, 16, pb.Screen_IPS)
+	newLaptop("Lenovo", 4000, 32, pb.Screen_IPS)
+
+	req := &pb.AggregateLaptopsRequest{
+		Filter:          &pb.Filter{MaxPriceUsd: 3000},
+		Facets:          []pb.AggregateLaptopsRequest_Facet{pb.AggregateLaptopsRequest_BRAND, pb.AggregateLaptopsRequest_SCREEN_PANEL},
+		PriceBucketsUsd: []float64{0, 1000, 2000},
+		RamBucketsGb:    []float64{0, 16},
+	}
+	res, err := server.AggregateLaptops(context.Background(), req)
+	require.NoError(t, err)
+
+	require.Equal(t, uint32(3), res.GetTotal())
+	require.Len(t, res.GetFacets(), 2)
+	require.Equal(t, []*pb.FacetCount{{Value: "Dell", Count: 2}, {Value: "Apple", Count: 1}}, res.GetFacets()[0].GetCounts())
+	require.Equal(t, []*pb.FacetCount{{Value: "IPS", Count: 2}, {Value: "OLED", Count: 1}}, res.GetFacets()[1].GetCounts())
+
+	require.Equal(t, []*pb.HistogramBucket{
+		{From: 0, To: 1000, Count: 0},
+		{From: 1000, To: 2000, Count: 2},
+		{From: 2000, Count: 1},
+	}, res.GetPriceHistogram())
+	require.Equal(t, []*pb.HistogramBucket{
+		{From: 0, To: 16, Count: 1},
+		{From: 16, Count: 2},
+	}, res.GetRamHistogram())
+
+	require.Equal(t, &pb.NumericStats{Count: 3, Min: 1000, Max: 2500, Average: 5000.0 / 3}, res.GetPriceUsd())
+	require.Equal(t, &pb.NumericStats{Count: 3, Min: 8, Max: 16, Average: 40.0 / 3}, res.GetRamGb())
+	require.Equal(t, &pb.NumericStats{Count: 3, Min: 2, Max: 2, Average: 2}, res.GetWeightKg())
+
+	req.PriceBucketsUsd = []float64{1000, 0}
+	_, err = server.AggregateLaptops(context.Background(), req)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	req.PriceBucketsUsd = nil
+	req.Facets = []pb.AggregateLaptopsRequest_Facet{pb.AggregateLaptopsRequest_BRAND, pb.AggregateLaptopsRequest_BRAND}
+	res, err = server.AggregateLaptops(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, res.GetFacets(), 1)
+
+	for _, facet := range []pb.AggregateLaptopsRequest_Facet{pb.AggregateLaptopsRequest_UNKNOWN, 99} {
+		req.Facets = []pb.AggregateLaptopsRequest_Facet{facet}
+		_, err = server.AggregateLaptops(context.Background(), req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+
+	// without a filter every laptop is aggregated
+	res, err = server.AggregateLaptops(context.Background(), &pb.AggregateLaptopsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint32(4), res.GetTotal())
+	require.Equal(t, &pb.NumericStats{Count: 4, Min: 1000, Max: 4000, Average: 9000.0 / 4}, res.GetPriceUsd())
+
+	// the server has no stock store to filter by
+	_, err = server.AggregateLaptops(context.Background(), &pb.AggregateLaptopsRequest{Filter: &pb.Filter{InStockOnly: true}})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
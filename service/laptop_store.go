@@ -5,28 +5,45 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"sort"
+	"strings"
 	"sync"
 
+	"example.com/pcbook/memsize"
 	"example.com/pcbook/pb"
 	"github.com/jinzhu/copier"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var ErrAlreadyExists = errors.New("record already exists")
+var ErrNotFound = errors.New("record not found")
+var ErrConflict = errors.New("record has been modified by someone else")
 
 type LaptopStore interface {
 	Save(laptop *pb.Laptop) error
+	Update(laptop *pb.Laptop, lastUpdatedAt *timestamppb.Timestamp) error
+	Delete(id string) error
 	Find(id string) (*pb.Laptop, error)
-	Search(ctx context.Context, filter *pb.Filter, found func(laptop *pb.Laptop) error) error
+	// Search calls found for every laptop that matches filter and has an id greater than afterID,
+	// in ascending order of id
+	Search(ctx context.Context, filter *pb.Filter, afterID string, found func(laptop *pb.Laptop) error) error
+	// Subscribe returns a subscription to the changes made after it is called
+	Subscribe() *LaptopSubscription
 }
 
 type InMemoryLaptopStore struct {
+	laptopNotifier
 	mutex sync.RWMutex
 	data  map[string]*pb.Laptop
+	index *textIndex
 }
 
 func NewInMemoryLaptopStore() *InMemoryLaptopStore {
 	return &InMemoryLaptopStore{
-		data: make(map[string]*pb.Laptop),
+		data:  make(map[string]*pb.Laptop),
+		index: newTextIndex(),
 	}
 }
 
@@ -42,10 +59,64 @@ func (store *InMemoryLaptopStore) Save(laptop *pb.Laptop) error {
 	if err != nil {
 		return fmt.Errorf("cannot copy laptop data : %v", err)
 	}
-	store.data[other.Id] = other
+	store.put(other)
+	store.publish(LaptopEvent{Type: LaptopCreated, ID: other.Id, Laptop: other})
 	return nil
 }
 
+// Update replaces the stored laptop only if its updated_at still equals lastUpdatedAt
+func (store *InMemoryLaptopStore) Update(laptop *pb.Laptop, lastUpdatedAt *timestamppb.Timestamp) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	current := store.data[laptop.Id]
+	if current == nil {
+		return ErrNotFound
+	}
+	if !proto.Equal(current.GetUpdatedAt(), lastUpdatedAt) {
+		return ErrConflict
+	}
+
+	other, err := deepCopy(laptop)
+	if err != nil {
+		return fmt.Errorf("cannot copy laptop data : %v", err)
+	}
+	store.put(other)
+	store.publish(LaptopEvent{Type: LaptopUpdated, ID: other.Id, Laptop: other})
+	return nil
+}
+
+func (store *InMemoryLaptopStore) Delete(id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.data[id] == nil {
+		return ErrNotFound
+	}
+
+	store.remove(id)
+	store.publish(LaptopEvent{Type: LaptopDeleted, ID: id})
+	return nil
+}
+
+// put and remove keep the text index in sync with the data, the caller must hold the write lock
+func (store *InMemoryLaptopStore) put(laptop *pb.Laptop) {
+	store.data[laptop.Id] = laptop
+	store.index.add(laptop)
+}
+
+func (store *InMemoryLaptopStore) remove(id string) {
+	delete(store.data, id)
+	store.index.remove(id)
+}
+
+func (store *InMemoryLaptopStore) MatchText(text string) (map[string]float64, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.index.match(text), nil
+}
+
 func (store *InMemoryLaptopStore) Find(id string) (*pb.Laptop, error) {
 	store.mutex.RLock()
 	defer store.mutex.RUnlock()
@@ -59,11 +130,20 @@ func (store *InMemoryLaptopStore) Find(id string) (*pb.Laptop, error) {
 	return deepCopy(laptop)
 }
 
-func (store *InMemoryLaptopStore) Search(ctx context.Context, filter *pb.Filter, found func(laptop *pb.Laptop) error) error {
+func (store *InMemoryLaptopStore) Search(ctx context.Context, filter *pb.Filter, afterID string, found func(laptop *pb.Laptop) error) error {
 	store.mutex.RLock()
 	defer store.mutex.RUnlock()
 
-	for _, laptop := range store.data {
+	ids := make([]string, 0, len(store.data))
+	for id := range store.data {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		laptop := store.data[id]
 		if ctx.Err() == context.DeadlineExceeded || ctx.Err() == context.Canceled {
 			log.Print("context is canceled")
 			return errors.New("context is canceled")
@@ -84,37 +164,146 @@ func (store *InMemoryLaptopStore) Search(ctx context.Context, filter *pb.Filter,
 
 }
 
+// maxPriceUsd returns the upper price bound of the filter, an unset max price doesn't restrict the search
+func maxPriceUsd(filter *pb.Filter) float64 {
+	if filter.GetMaxPriceUsd() == 0 {
+		return math.MaxFloat64
+	}
+	return filter.GetMaxPriceUsd()
+}
+
 func isQualified(filter *pb.Filter, laptop *pb.Laptop) bool {
-	if laptop.GetPriceUsd() > filter.GetMaxPriceUsd() {
+	if laptop.GetPriceUsd() > maxPriceUsd(filter) {
+		return false
+	}
+	if laptop.GetPriceUsd() < filter.GetMinPriceUsd() {
 		return false
 	}
 	if laptop.GetCpu().GetNumberCores() < filter.GetMinCpuCore() {
 		return false
 	}
-	if laptop.GetCpu().GetMinGhz() < filter.MinCpuGhz {
+	if laptop.GetCpu().GetMinGhz() < filter.GetMinCpuGhz() {
+		return false
+	}
+	if memsize.Compare(laptop.GetRam(), filter.GetMinRam()) < 0 {
+		return false
+	}
+	if !containsFold(filter.GetBrands(), laptop.GetBrand()) {
+		return false
+	}
+	if !containsFold(filter.GetNames(), laptop.GetName()) {
+		return false
+	}
+	if laptop.GetReleaseYear() < filter.GetMinReleaseYear() {
+		return false
+	}
+	if filter.GetMaxReleaseYear() > 0 && laptop.GetReleaseYear() > filter.GetMaxReleaseYear() {
+		return false
+	}
+	if !hasQualifiedGPU(filter, laptop) {
+		return false
+	}
+	if !hasQualifiedScreen(filter, laptop.GetScreen()) {
+		return false
+	}
+	if !hasQualifiedStorage(filter, laptop) {
 		return false
 	}
-	if toBit(laptop.GetRam()) < toBit(filter.GetMinRam()) {
+	if filter.GetMaxWeightKg() > 0 {
+		weight := weightKg(laptop)
+		if weight == 0 || weight > filter.GetMaxWeightKg() {
+			return false
+		}
+	}
+	if filter.GetKeyboardLayout() != pb.Keyboard_UNKNOWN && laptop.GetKeyboard().GetLayout() != filter.GetKeyboardLayout() {
+		return false
+	}
+	if filter != nil && filter.KeyboardBacklit != nil && laptop.GetKeyboard().GetBacklit() != filter.GetKeyboardBacklit() {
 		return false
 	}
 	return true
 }
 
-func toBit(memory *pb.Memory) uint64 {
-	value := memory.GetValue()
-	switch memory.GetUnit() {
-	case pb.Memory_BIT:
-		return value
-	case pb.Memory_BYTE:
-		return value << 3
-	case pb.Memory_KILOBYTE:
-		return value << 13
-	case pb.Memory_MEGABYTE:
-		return value << 23
-	case pb.Memory_GIGABYTE:
-		return value << 33
-	case pb.Memory_TERABYTE:
-		return value << 43
+// containsFold reports whether value is one of values, ignoring case. An empty set contains everything.
+func containsFold(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasQualifiedGPU(filter *pb.Filter, laptop *pb.Laptop) bool {
+	if filter.GetMinGpuMemory() == nil && filter.GetGpuBrand() == "" {
+		return true
+	}
+	for _, gpu := range laptop.GetGpus() {
+		if filter.GetGpuBrand() != "" && !strings.EqualFold(gpu.GetBrand(), filter.GetGpuBrand()) {
+			continue
+		}
+		if memsize.Compare(gpu.GetMemory(), filter.GetMinGpuMemory()) < 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func hasQualifiedScreen(filter *pb.Filter, screen *pb.Screen) bool {
+	if screen.GetSizeInch() < filter.GetMinScreenSizeInch() {
+		return false
+	}
+	if filter.GetMaxScreenSizeInch() > 0 && screen.GetSizeInch() > filter.GetMaxScreenSizeInch() {
+		return false
+	}
+	if screen.GetResolution().GetWidth() < filter.GetMinScreenResolution().GetWidth() ||
+		screen.GetResolution().GetHeight() < filter.GetMinScreenResolution().GetHeight() {
+		return false
+	}
+	if filter.GetScreenPanel() != pb.Screen_UNKNOWN && screen.GetPanel() != filter.GetScreenPanel() {
+		return false
+	}
+	return true
+}
+
+func hasQualifiedStorage(filter *pb.Filter, laptop *pb.Laptop) bool {
+	if filter.GetMinStorage() == nil && !filter.GetSsdOnly() {
+		return true
+	}
+
+	var total *pb.Memory
+	ssdCount := 0
+	for _, storage := range laptop.GetStorages() {
+		if storage.GetDriver() == pb.Storage_SDD {
+			ssdCount++
+		} else if filter.GetSsdOnly() {
+			continue
+		}
+		sum, err := memsize.Add(total, storage.GetMemory())
+		if err != nil {
+			// an unknown unit counts as nothing, as in comparisons
+			continue
+		}
+		total = sum
+	}
+
+	if filter.GetSsdOnly() && ssdCount == 0 {
+		return false
+	}
+	return memsize.Compare(total, filter.GetMinStorage()) >= 0
+}
+
+// weightKg returns the weight of the laptop in kilograms, or 0 if it is unknown
+func weightKg(laptop *pb.Laptop) float64 {
+	switch weight := laptop.GetWeight().(type) {
+	case *pb.Laptop_WeightKg:
+		return weight.WeightKg
+	case *pb.Laptop_WeightLb:
+		return weight.WeightLb * memsize.KgPerLb
 	default:
 		return 0
 	}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"testing"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestIsQualified(t *testing.T) {
+	t.Parallel()
+
+	laptop := sample.NewLaptop()
+	laptop.Brand = "Lenovo"
+	laptop.Name = "Thinkpad X1"
+	laptop.PriceUsd = 2000
+	laptop.ReleaseYear = 2018
+	laptop.Gpus = []*pb.GPU{{Brand: "NVIDIA", Memory: &pb.Memory{Value: 4, Unit: pb.Memory_GIGABYTE}}}
+	laptop.Screen = &pb.Screen{SizeInch: 15, Resolution: &pb.Screen_Resolution{Width: 1920, Height: 1080}, Panel: pb.Screen_IPS}
+	laptop.Storages = []*pb.Storage{
+		{Driver: pb.Storage_SDD, Memory: &pb.Memory{Value: 512, Unit: pb.Memory_GIGABYTE}},
+		{Driver: pb.Storage_HDD, Memory: &pb.Memory{Value: 1, Unit: pb.Memory_TERABYTE}},
+	}
+	laptop.Weight = &pb.Laptop_WeightLb{WeightLb: 4}
+	laptop.Keyboard = &pb.Keyboard{Layout: pb.Keyboard_QWERTY, Backlit: true}
+
+	backlit := true
+	unlit := false
+
+	testCases := []struct {
+		name      string
+		filter    *pb.Filter
+		qualified bool
+	}{
+		{name: "match_all", filter: &pb.Filter{}, qualified: true},
+		{name: "brand", filter: &pb.Filter{Brands: []string{"Dell", "lenovo"}}, qualified: true},
+		{name: "other_brand", filter: &pb.Filter{Brands: []string{"Dell"}}},
+		{name: "name", filter: &pb.Filter{Names: []string{"XPS"}}},
+		{name: "release_year", filter: &pb.Filter{MinReleaseYear: 2017, MaxReleaseYear: 2018}, qualified: true},
+		{name: "too_old", filter: &pb.Filter{MinReleaseYear: 2019}},
+		{name: "too_cheap", filter: &pb.Filter{MinPriceUsd: 2500}},
+		{name: "gpu", filter: &pb.Filter{GpuBrand: "nvidia", MinGpuMemory: &pb.Memory{Value: 4, Unit: pb.Memory_GIGABYTE}}, qualified: true},
+		{name: "gpu_memory", filter: &pb.Filter{MinGpuMemory: &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE}}},
+		{name: "gpu_brand", filter: &pb.Filter{GpuBrand: "AMD"}},
+		{name: "screen", filter: &pb.Filter{MinScreenSizeInch: 14, MaxScreenSizeInch: 16, ScreenPanel: pb.Screen_IPS}, qualified: true},
+		{name: "screen_resolution", filter: &pb.Filter{MinScreenResolution: &pb.Screen_Resolution{Width: 3840, Height: 2160}}},
+		{name: "screen_panel", filter: &pb.Filter{ScreenPanel: pb.Screen_OLED}},
+		{name: "ram", filter: &pb.Filter{MinRam: &pb.Memory{Value: 4, Unit: pb.Memory_GIGABYTE}}, qualified: true},
+		{name: "ram_overflow", filter: &pb.Filter{MinRam: &pb.Memory{Value: 1 << 21, Unit: pb.Memory_TERABYTE}}},
+		{name: "storage", filter: &pb.Filter{MinStorage: &pb.Memory{Value: 1500, Unit: pb.Memory_GIGABYTE}}, qualified: true},
+		{name: "ssd_storage", filter: &pb.Filter{MinStorage: &pb.Memory{Value: 1, Unit: pb.Memory_TERABYTE}, SsdOnly: true}},
+		{name: "weight", filter: &pb.Filter{MaxWeightKg: 2}, qualified: true},
+		{name: "too_heavy", filter: &pb.Filter{MaxWeightKg: 1.5}},
+		{name: "keyboard", filter: &pb.Filter{KeyboardLayout: pb.Keyboard_QWERTY, KeyboardBacklit: &backlit}, qualified: true},
+		{name: "keyboard_backlit", filter: &pb.Filter{KeyboardBacklit: &unlit}},
+		{name: "keyboard_layout", filter: &pb.Filter{KeyboardLayout: pb.Keyboard_AZERTY}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := proto.Clone(tc.filter).(*pb.Filter)
+			filter.MaxPriceUsd = 3000
+			require.Equal(t, tc.qualified, isQualified(filter, laptop))
+		})
+	}
+
+	// the fields that are not set don't restrict the search, max_price_usd included
+	require.True(t, isQualified(nil, laptop))
+	require.True(t, isQualified(&pb.Filter{}, laptop))
+	require.False(t, isQualified(&pb.Filter{MaxPriceUsd: laptop.GetPriceUsd() / 2}, laptop))
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"sync"
+
+	"example.com/pcbook/pb"
+)
+
+// watchBufferSize is how many events a subscriber may fall behind before it is dropped
+const watchBufferSize = 256
+
+type LaptopEventType int
+
+const (
+	LaptopCreated LaptopEventType = iota + 1
+	LaptopUpdated
+	LaptopDeleted
+)
+
+// LaptopEvent describes a change to the store. Laptop is nil for deleted laptops and must not be modified.
+type LaptopEvent struct {
+	Type   LaptopEventType
+	ID     string
+	Laptop *pb.Laptop
+}
+
+// LaptopSubscription receives the changes made to a store after it was created
+type LaptopSubscription struct {
+	notifier *laptopNotifier
+	events   chan LaptopEvent
+	lagging  bool
+}
+
+// Events is closed when the subscription is closed, or when the subscriber falls too far behind.
+// In the latter case Lagging returns true.
+func (subscription *LaptopSubscription) Events() <-chan LaptopEvent {
+	return subscription.events
+}
+
+func (subscription *LaptopSubscription) Lagging() bool {
+	subscription.notifier.mutex.Lock()
+	defer subscription.notifier.mutex.Unlock()
+
+	return subscription.lagging
+}
+
+func (subscription *LaptopSubscription) Close() {
+	subscription.notifier.unsubscribe(subscription)
+}
+
+// laptopNotifier fans out store changes to subscribers without ever blocking the writer:
+// a subscriber whose buffer is full is dropped and has to start over
+type laptopNotifier struct {
+	mutex       sync.Mutex
+	subscribers map[*LaptopSubscription]bool
+}
+
+func (notifier *laptopNotifier) Subscribe() *LaptopSubscription {
+	notifier.mutex.Lock()
+	defer notifier.mutex.Unlock()
+
+	subscription := &LaptopSubscription{
+		notifier: notifier,
+		events:   make(chan LaptopEvent, watchBufferSize),
+	}
+	if notifier.subscribers == nil {
+		notifier.subscribers = make(map[*LaptopSubscription]bool)
+	}
+	notifier.subscribers[subscription] = true
+	return subscription
+}
+
+func (notifier *laptopNotifier) unsubscribe(subscription *LaptopSubscription) {
+	notifier.mutex.Lock()
+	defer notifier.mutex.Unlock()
+
+	if notifier.subscribers[subscription] {
+		delete(notifier.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+func (notifier *laptopNotifier) publish(event LaptopEvent) {
+	notifier.mutex.Lock()
+	defer notifier.mutex.Unlock()
+
+	for subscription := range notifier.subscribers {
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.lagging = true
+			delete(notifier.subscribers, subscription)
+			close(subscription.events)
+		}
+	}
+}
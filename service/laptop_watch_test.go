@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLaptopNotifierDropsSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	notifier := &laptopNotifier{}
+	slow := notifier.Subscribe()
+	fast := notifier.Subscribe()
+	defer fast.Close()
+
+	// publishing never blocks, even though nobody reads from slow
+	for i := 0; i <= watchBufferSize; i++ {
+		notifier.publish(LaptopEvent{Type: LaptopCreated, ID: "id", Laptop: &pb.Laptop{Id: "id"}})
+		<-fast.Events()
+	}
+
+	count := 0
+	for range slow.Events() {
+		count++
+	}
+	require.Equal(t, watchBufferSize, count)
+	require.True(t, slow.Lagging())
+	require.False(t, fast.Lagging())
+
+	// closing a dropped subscription is harmless
+	slow.Close()
+}
+
+// blockingWatchStream blocks every Send until release is closed
+type blockingWatchStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	sending chan struct{}
+	release chan struct{}
+}
+
+func (stream *blockingWatchStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *blockingWatchStream) Send(res *pb.WatchResponse) error {
+	stream.sending <- struct{}{}
+	<-stream.release
+	return nil
+}
+
+func TestWatchLaptopsSlowWatcherDoesNotBlockWrites(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	require.NoError(t, laptopStore.Save(sample.NewLaptop()))
+	server := NewLaptopServer(laptopStore, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &blockingWatchStream{ctx: ctx, sending: make(chan struct{}, 10), release: make(chan struct{})}
+	done := make(chan error)
+	go func() {
+		done <- server.WatchLaptops(&pb.WatchRequest{}, stream)
+	}()
+	<-stream.sending
+
+	saved := make(chan error)
+	go func() {
+		saved <- laptopStore.Save(sample.NewLaptop())
+	}()
+	select {
+	case err := <-saved:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("save is blocked by the watcher")
+	}
+
+	cancel()
+	close(stream.release)
+	require.Equal(t, codes.Canceled, status.Code(<-done))
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"example.com/pcbook/pb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxCartQuantity = 100
+
+// OrderServer acts on behalf of the user in the claims put in the context by the AuthInterceptor
+type OrderServer struct {
+	pb.UnimplementedOrderServiceServer
+	laptopStore LaptopStore
+	orderStore  OrderStore
+	cartStore   CartStore
+}
+
+func NewOrderServer(laptopStore LaptopStore, orderStore OrderStore, cartStore CartStore) *OrderServer {
+	return &OrderServer{laptopStore: laptopStore, orderStore: orderStore, cartStore: cartStore}
+}
+
+func userClaims(ctx context.Context) (*UserClaim, error) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "the user is not authenticated")
+	}
+	return claims, nil
+}
+
+func isAdmin(claims *UserClaim) bool {
+	return claims.Role == "admin"
+}
+
+func (server *OrderServer) UpdateCart(ctx context.Context, req *pb.UpdateCartRequest) (*pb.UpdateCartResponse, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("receive an update cart request from %s: %v", claims.Username, req)
+
+	if req.GetQuantity() > maxCartQuantity {
+		return nil, status.Errorf(codes.InvalidArgument, "quantity cannot be more than %d", maxCartQuantity)
+	}
+	if req.GetQuantity() > 0 {
+		laptop, err := server.laptopStore.Find(req.GetLaptopId())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+		}
+		if laptop == nil {
+			return nil, status.Errorf(codes.NotFound, "laptop %s is not found", req.GetLaptopId())
+		}
+	}
+
+	cart, err := server.cartStore.SetItem(claims.Username, req.GetLaptopId(), req.GetQuantity())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot update cart: %v", err)
+	}
+	return &pb.UpdateCartResponse{Cart: cart}, nil
+}
+
+func (server *OrderServer) GetCart(ctx context.Context, req *pb.GetCartRequest) (*pb.GetCartResponse, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := server.cartStore.Get(claims.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot get cart: %v", err)
+	}
+	return &pb.GetCartResponse{Cart: cart}, nil
+}
+
+// PlaceOrder empties the cart into a new order. The laptops are copied into the order,
+// so later changes to the catalog don't change what was ordered or its price.
+func (server *OrderServer) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (*pb.PlaceOrderResponse, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("receive a place order request from %s", claims.Username)
+
+	// taking the cart keeps two concurrent requests from ordering it twice
+	cart, err := server.cartStore.Take(claims.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot take cart: %v", err)
+	}
+
+	order, err := server.newOrder(ctx, claims.Username, cart)
+	if err == nil {
+		err = server.orderStore.Save(order)
+		if err != nil {
+			err = status.Errorf(codes.Internal, "cannot save order: %v", err)
+		}
+	}
+	if err != nil {
+		restoreErr := server.cartStore.Restore(claims.Username, cart)
+		if restoreErr != nil {
+			log.Printf("cannot restore the cart of %s: %v", claims.Username, restoreErr)
+		}
+		return nil, logError(err)
+	}
+
+	log.Printf("placed order with id: %s", order.GetId())
+	return &pb.PlaceOrderResponse{Order: order}, nil
+}
+
+func (server *OrderServer) newOrder(ctx context.Context, username string, cart *pb.Cart) (*pb.Order, error) {
+	if len(cart.GetItems()) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "the cart is empty")
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot generate a new order ID: %v", err)
+	}
+	now := timestamppb.Now()
+	order := &pb.Order{
+		Id:        id.String(),
+		Username:  username,
+		State:     pb.Order_PLACED,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	for _, item := range cart.GetItems() {
+		if err := contextErr(ctx); err != nil {
+			return nil, err
+		}
+		laptop, err := server.laptopStore.Find(item.GetLaptopId())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+		}
+		if laptop == nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "laptop %s is no longer in the catalog", item.GetLaptopId())
+		}
+		order.Items = append(order.Items, &pb.OrderItem{
+			Laptop:       proto.Clone(laptop).(*pb.Laptop),
+			Quantity:     item.GetQuantity(),
+			UnitPriceUsd: laptop.GetPriceUsd(),
+		})
+		order.TotalUsd += laptop.GetPriceUsd() * float64(item.GetQuantity())
+	}
+	return order, nil
+}
+
+func (server *OrderServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := server.orderStore.Find(req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find order: %v", err)
+	}
+	// other users' orders are reported as missing, so that their ids cannot be probed
+	if order == nil || !isAdmin(claims) && order.GetUsername() != claims.Username {
+		return nil, status.Errorf(codes.NotFound, "order %s is not found", req.GetId())
+	}
+	return &pb.GetOrderResponse{Order: order}, nil
+}
+
+func (server *OrderServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	username := req.GetUsername()
+	if !isAdmin(claims) {
+		if username != "" && username != claims.Username {
+			return nil, status.Error(codes.PermissionDenied, "only admins can list the orders of other users")
+		}
+		username = claims.Username
+	}
+
+	orders, err := server.orderStore.List(username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot list orders: %v", err)
+	}
+	return &pb.ListOrdersResponse{Orders: orders}, nil
+}
+
+func (server *OrderServer) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("receive a cancel order request from %s with id: %s", claims.Username, req.GetId())
+
+	order, err := server.changeState(claims, req.GetId(), pb.Order_CANCELED)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CancelOrderResponse{Order: order}, nil
+}
+
+func (server *OrderServer) UpdateOrderState(ctx context.Context, req *pb.UpdateOrderStateRequest) (*pb.UpdateOrderStateResponse, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("receive an update order state request from %s: %v", claims.Username, req)
+
+	if !isAdmin(claims) {
+		return nil, status.Error(codes.PermissionDenied, "only admins can change the state of an order")
+	}
+	order, err := server.changeState(claims, req.GetId(), req.GetState())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateOrderStateResponse{Order: order}, nil
+}
+
+func (server *OrderServer) changeState(claims *UserClaim, id string, state pb.Order_State) (*pb.Order, error) {
+	errNotVisible := errors.New("order belongs to another user")
+
+	order, err := server.orderStore.Update(id, func(order *pb.Order) error {
+		if !isAdmin(claims) && order.GetUsername() != claims.Username {
+			return errNotVisible
+		}
+		err := checkOrderTransition(order.GetState(), state)
+		if err != nil {
+			return err
+		}
+		order.State = state
+		order.UpdatedAt = timestamppb.Now()
+		return nil
+	})
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, errNotVisible):
+		return nil, logError(status.Errorf(codes.NotFound, "order %s is not found", id))
+	case errors.Is(err, ErrIllegalTransition):
+		return nil, logError(status.Errorf(codes.FailedPrecondition, "cannot change order state: %v", err))
+	case err != nil:
+		return nil, logError(status.Errorf(codes.Internal, "cannot update order: %v", err))
+	}
+
+	log.Printf("order %s is %s", id, state)
+	return order, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientOrders(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	laptop.PriceUsd = 1500
+	require.NoError(t, laptopStore.Save(laptop))
+
+	jwtManager := NewJWTManager("secret", time.Minute)
+	const orderServicePath = "/example.pcbook.OrderService/"
+	interceptor := NewAuthInterceptor(jwtManager, map[string][]string{
+		orderServicePath + "UpdateCart":       {"admin", "user"},
+		orderServicePath + "PlaceOrder":       {"admin", "user"},
+		orderServicePath + "GetOrder":         {"admin", "user"},
+		orderServicePath + "ListOrders":       {"admin", "user"},
+		orderServicePath + "CancelOrder":      {"admin", "user"},
+		orderServicePath + "UpdateOrderState": {"admin"},
+	})
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(interceptor.Unary()))
+	pb.RegisterOrderServiceServer(grpcServer, NewOrderServer(laptopStore, NewInMemoryOrderStore(), NewInMemoryCartStore()))
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	orderClient := pb.NewOrderServiceClient(conn)
+
+	as := func(username string, role string) context.Context {
+		token, err := jwtManager.Generate(&User{Username: username, Role: role})
+		require.NoError(t, err)
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", token)
+	}
+	alice, bob, admin := as("alice", "user"), as("bob", "user"), as("admin1", "admin")
+
+	_, err = orderClient.PlaceOrder(context.Background(), &pb.PlaceOrderRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = orderClient.PlaceOrder(alice, &pb.PlaceOrderRequest{})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = orderClient.UpdateCart(alice, &pb.UpdateCartRequest{LaptopId: laptop.GetId(), Quantity: 2})
+	require.NoError(t, err)
+	placed, err := orderClient.PlaceOrder(alice, &pb.PlaceOrderRequest{})
+	require.NoError(t, err)
+	order := placed.GetOrder()
+	require.Equal(t, "alice", order.GetUsername())
+	require.Equal(t, pb.Order_PLACED, order.GetState())
+	require.Equal(t, 3000.0, order.GetTotalUsd())
+
+	// the order keeps the price it was placed at
+	updated := sample.NewLaptop()
+	updated.Id = laptop.GetId()
+	updated.PriceUsd = 2500
+	require.NoError(t, laptopStore.Update(updated, laptop.GetUpdatedAt()))
+	got, err := orderClient.GetOrder(alice, &pb.GetOrderRequest{Id: order.GetId()})
+	require.NoError(t, err)
+	require.Equal(t, 1500.0, got.GetOrder().GetItems()[0].GetUnitPriceUsd())
+	require.Equal(t, 1500.0, got.GetOrder().GetItems()[0].GetLaptop().GetPriceUsd())
+
+	_, err = orderClient.GetOrder(bob, &pb.GetOrderRequest{Id: order.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = orderClient.CancelOrder(bob, &pb.CancelOrderRequest{Id: order.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	list, err := orderClient.ListOrders(bob, &pb.ListOrdersRequest{})
+	require.NoError(t, err)
+	require.Empty(t, list.GetOrders())
+	_, err = orderClient.ListOrders(bob, &pb.ListOrdersRequest{Username: "alice"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	list, err = orderClient.ListOrders(admin, &pb.ListOrdersRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetOrders(), 1)
+
+	_, err = orderClient.UpdateOrderState(alice, &pb.UpdateOrderStateRequest{Id: order.GetId(), State: pb.Order_PAID})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = orderClient.UpdateOrderState(admin, &pb.UpdateOrderStateRequest{Id: order.GetId(), State: pb.Order_DELIVERED})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	for _, state := range []pb.Order_State{pb.Order_PAID, pb.Order_SHIPPED} {
+		_, err = orderClient.UpdateOrderState(admin, &pb.UpdateOrderStateRequest{Id: order.GetId(), State: state})
+		require.NoError(t, err)
+	}
+
+	_, err = orderClient.CancelOrder(alice, &pb.CancelOrderRequest{Id: order.GetId()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestCheckOrderTransition(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, checkOrderTransition(pb.Order_PLACED, pb.Order_CANCELED))
+	require.NoError(t, checkOrderTransition(pb.Order_PAID, pb.Order_CANCELED))
+	require.NoError(t, checkOrderTransition(pb.Order_SHIPPED, pb.Order_DELIVERED))
+	require.Error(t, checkOrderTransition(pb.Order_SHIPPED, pb.Order_CANCELED))
+	require.Error(t, checkOrderTransition(pb.Order_CANCELED, pb.Order_PLACED))
+	require.Error(t, checkOrderTransition(pb.Order_DELIVERED, pb.Order_DELIVERED))
+	require.Error(t, checkOrderTransition(pb.Order_PLACED, pb.Order_UNKNOWN))
+}
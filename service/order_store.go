@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"example.com/pcbook/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+var ErrIllegalTransition = errors.New("illegal order state transition")
+
+// orderTransitions lists the states an order can move to from each state, the others are final
+var orderTransitions = map[pb.Order_State][]pb.Order_State{
+	pb.Order_PLACED:  {pb.Order_PAID, pb.Order_CANCELED},
+	pb.Order_PAID:    {pb.Order_SHIPPED, pb.Order_CANCELED},
+	pb.Order_SHIPPED: {pb.Order_DELIVERED},
+}
+
+func checkOrderTransition(from pb.Order_State, to pb.Order_State) error {
+	for _, state := range orderTransitions[from] {
+		if state == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+}
+
+type OrderStore interface {
+	Save(order *pb.Order) error
+	Find(id string) (*pb.Order, error)
+	// List returns the orders of username, or every order when it is empty, oldest first
+	List(username string) ([]*pb.Order, error)
+	// Update saves the order as changed by update, unless update fails
+	Update(id string, update func(order *pb.Order) error) (*pb.Order, error)
+}
+
+type CartStore interface {
+	Get(username string) (*pb.Cart, error)
+	// SetItem changes the quantity of a laptop in the cart, 0 removes it
+	SetItem(username string, laptopID string, quantity uint32) (*pb.Cart, error)
+	// Take empties the cart and returns what it held
+	Take(username string) (*pb.Cart, error)
+	// Restore adds the items of a cart returned by Take back, when the order cannot be placed
+	Restore(username string, cart *pb.Cart) error
+}
+
+type InMemoryOrderStore struct {
+	mutex  sync.RWMutex
+	orders map[string]*pb.Order
+}
+
+func NewInMemoryOrderStore() *InMemoryOrderStore {
+	return &InMemoryOrderStore{
+		orders: make(map[string]*pb.Order),
+	}
+}
+
+func (store *InMemoryOrderStore) Save(order *pb.Order) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.orders[order.Id] != nil {
+		return ErrAlreadyExists
+	}
+	store.orders[order.Id] = proto.Clone(order).(*pb.Order)
+	return nil
+}
+
+func (store *InMemoryOrderStore) Find(id string) (*pb.Order, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	order := store.orders[id]
+	if order == nil {
+		return nil, nil
+	}
+	return proto.Clone(order).(*pb.Order), nil
+}
+
+func (store *InMemoryOrderStore) List(username string) ([]*pb.Order, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var orders []*pb.Order
+	for _, order := range store.orders {
+		if username == "" || order.Username == username {
+			orders = append(orders, proto.Clone(order).(*pb.Order))
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		ti, tj := orders[i].GetCreatedAt().AsTime(), orders[j].GetCreatedAt().AsTime()
+		if ti.Equal(tj) {
+			return orders[i].Id < orders[j].Id
+		}
+		return ti.Before(tj)
+	})
+	return orders, nil
+}
+
+func (store *InMemoryOrderStore) Update(id string, update func(order *pb.Order) error) (*pb.Order, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	current := store.orders[id]
+	if current == nil {
+		return nil, ErrNotFound
+	}
+	order := proto.Clone(current).(*pb.Order)
+	err := update(order)
+	if err != nil {
+		return nil, err
+	}
+	store.orders[id] = order
+	return proto.Clone(order).(*pb.Order), nil
+}
+
+type InMemoryCartStore struct {
+	mutex sync.Mutex
+	// carts maps a username to the quantity of each laptop in the cart
+	carts map[string]map[string]uint32
+}
+
+func NewInMemoryCartStore() *InMemoryCartStore {
+	return &InMemoryCartStore{
+		carts: make(map[string]map[string]uint32),
+	}
+}
+
+func (store *InMemoryCartStore) Get(username string) (*pb.Cart, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.cart(username), nil
+}
+
+// cart returns the cart of username with the items ordered by laptop id, the mutex must be held
+func (store *InMemoryCartStore) cart(username string) *pb.Cart {
+	cart := &pb.Cart{}
+	for laptopID, quantity := range store.carts[username] {
+		cart.Items = append(cart.Items, &pb.CartItem{LaptopId: laptopID, Quantity: quantity})
+	}
+	sort.Slice(cart.Items, func(i, j int) bool {
+		return cart.Items[i].LaptopId < cart.Items[j].LaptopId
+	})
+	return cart
+}
+
+func (store *InMemoryCartStore) SetItem(username string, laptopID string, quantity uint32) (*pb.Cart, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	items := store.carts[username]
+	if items == nil {
+		items = make(map[string]uint32)
+		store.carts[username] = items
+	}
+	if quantity == 0 {
+		delete(items, laptopID)
+	} else {
+		items[laptopID] = quantity
+	}
+	return store.cart(username), nil
+}
+
+func (store *InMemoryCartStore) Take(username string) (*pb.Cart, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	cart := store.cart(username)
+	delete(store.carts, username)
+	return cart, nil
+}
+
+func (store *InMemoryCartStore) Restore(username string, cart *pb.Cart) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	items := store.carts[username]
+	if items == nil {
+		items = make(map[string]uint32)
+		store.carts[username] = items
+	}
+	for _, item := range cart.GetItems() {
+		items[item.GetLaptopId()] += item.GetQuantity()
+	}
+	return nil
+}
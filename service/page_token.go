@@ -0,0 +1,50 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"example.com/pcbook/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// errPageComplete stops a search once a full page has been collected
+var errPageComplete = errors.New("page is complete")
+
+func encodePageToken(last sortedLaptop, orderBy *pb.OrderBy) (string, error) {
+	token := &pb.SearchPageToken{
+		LastId:    last.laptop.GetId(),
+		LastValue: last.value,
+		OrderBy:   orderBy,
+	}
+	data, err := proto.Marshal(token)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal page token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodePageToken returns nil for an empty page token
+func decodePageToken(pageToken string, orderBy *pb.OrderBy) (*pb.SearchPageToken, error) {
+	if pageToken == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode page token: %w", err)
+	}
+
+	token := &pb.SearchPageToken{}
+	err = proto.Unmarshal(data, token)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal page token: %w", err)
+	}
+
+	if token.GetOrderBy().GetField() != orderBy.GetField() ||
+		token.GetOrderBy().GetDescending() != orderBy.GetDescending() {
+		return nil, fmt.Errorf("page token was issued for a different order")
+	}
+	return token, nil
+}
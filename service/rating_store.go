@@ -1,9 +1,18 @@
 package service
 
-import "sync"
+import (
+	"context"
+	"sort"
+	"sync"
+)
 
 type RatingStore interface {
 	Add(laptopID string, score float64) (*Rating, error)
+	Find(laptopID string) (*Rating, error)
+	// Put replaces the rating totals of a laptop
+	Put(laptopID string, rating *Rating) error
+	// Range calls found for every rating, in ascending order of laptop id
+	Range(ctx context.Context, found func(laptopID string, rating *Rating) error) error
 }
 
 type Rating struct {
@@ -40,3 +49,53 @@ func (store *InMemoryRatingStore) Add(laptopID string, score float64) (*Rating,
 	store.rating[laptopID] = rating
 	return rating, nil
 }
+
+func (store *InMemoryRatingStore) Find(laptopID string) (*Rating, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	rating := store.rating[laptopID]
+	if rating == nil {
+		return nil, nil
+	}
+	return &Rating{
+		Count: rating.Count,
+		Sum:   rating.Sum,
+	}, nil
+}
+
+func (store *InMemoryRatingStore) Put(laptopID string, rating *Rating) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.rating[laptopID] = &Rating{
+		Count: rating.Count,
+		Sum:   rating.Sum,
+	}
+	return nil
+}
+
+func (store *InMemoryRatingStore) Range(ctx context.Context, found func(laptopID string, rating *Rating) error) error {
+	// copy the ratings, so that found doesn't hold up new ones
+	store.mutex.RLock()
+	ratings := make(map[string]Rating, len(store.rating))
+	laptopIDs := make([]string, 0, len(store.rating))
+	for laptopID, rating := range store.rating {
+		ratings[laptopID] = *rating
+		laptopIDs = append(laptopIDs, laptopID)
+	}
+	store.mutex.RUnlock()
+	sort.Strings(laptopIDs)
+
+	for _, laptopID := range laptopIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rating := ratings[laptopID]
+		err := found(laptopID, &rating)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// migrations are applied in order, the index+1 of the last applied one is kept in PRAGMA user_version.
+// Never edit an existing entry, append a new one instead.
+var migrations = []string{
+	`CREATE TABLE laptops (
+		id          TEXT PRIMARY KEY,
+		price_usd   REAL NOT NULL,
+		cpu_cores   INTEGER NOT NULL,
+		cpu_min_ghz REAL NOT NULL,
+		ram_bits    INTEGER NOT NULL,
+		updated_at  INTEGER NOT NULL,
+		data        BLOB NOT NULL
+	);
+	CREATE INDEX laptops_price_usd ON laptops (price_usd);
+	CREATE INDEX laptops_cpu_cores ON laptops (cpu_cores);
+	CREATE INDEX laptops_cpu_min_ghz ON laptops (cpu_min_ghz);
+	CREATE INDEX laptops_ram_bits ON laptops (ram_bits);`,
+
+	`CREATE TABLE ratings (
+		laptop_id TEXT PRIMARY KEY,
+		count     INTEGER NOT NULL,
+		sum       REAL NOT NULL
+	);`,
+
+	`CREATE TABLE users (
+		username        TEXT PRIMARY KEY,
+		hashed_password TEXT NOT NULL,
+		role            TEXT NOT NULL
+	);`,
+}
+
+// OpenSQLiteDB opens the SQLite database file at path and brings its schema up to date
+func OpenSQLiteDB(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", path))
+	if err != nil {
+		return nil, fmt.Errorf("cannot open sqlite database: %w", err)
+	}
+
+	err = migrate(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	err := db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return fmt.Errorf("cannot read schema version: %w", err)
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("cannot begin migration: %w", err)
+		}
+		_, err = tx.Exec(migrations[version])
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("cannot apply migration %d: %w", version+1, err)
+		}
+		_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1))
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("cannot update schema version: %w", err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			return fmt.Errorf("cannot commit migration %d: %w", version+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"example.com/pcbook/memsize"
+	"example.com/pcbook/pb"
+	"github.com/mattn/go-sqlite3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type SQLiteLaptopStore struct {
+	laptopNotifier
+	// mutex serializes the writes, so that the events are published in the order they are committed
+	mutex sync.Mutex
+	db    *sql.DB
+}
+
+func NewSQLiteLaptopStore(db *sql.DB) *SQLiteLaptopStore {
+	return &SQLiteLaptopStore{db: db}
+}
+
+func (store *SQLiteLaptopStore) Save(laptop *pb.Laptop) error {
+	data, err := proto.Marshal(laptop)
+	if err != nil {
+		return fmt.Errorf("cannot marshal laptop: %w", err)
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	_, err = store.db.Exec(
+		`INSERT INTO laptops (id, price_usd, cpu_cores, cpu_min_ghz, ram_bits, updated_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		laptop.GetId(),
+		laptop.GetPriceUsd(),
+		laptop.GetCpu().GetNumberCores(),
+		laptop.GetCpu().GetMinGhz(),
+		ramBits(laptop.GetRam()),
+		timestampToNanos(laptop.GetUpdatedAt()),
+		data,
+	)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("cannot insert laptop: %w", err)
+	}
+	store.publish(LaptopEvent{Type: LaptopCreated, ID: laptop.GetId(), Laptop: proto.Clone(laptop).(*pb.Laptop)})
+	return nil
+}
+
+func (store *SQLiteLaptopStore) Update(laptop *pb.Laptop, lastUpdatedAt *timestamppb.Timestamp) error {
+	data, err := proto.Marshal(laptop)
+	if err != nil {
+		return fmt.Errorf("cannot marshal laptop: %w", err)
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	result, err := store.db.Exec(
+		`UPDATE laptops SET price_usd = ?, cpu_cores = ?, cpu_min_ghz = ?, ram_bits = ?, updated_at = ?, data = ?
+		WHERE id = ? AND updated_at = ?`,
+		laptop.GetPriceUsd(),
+		laptop.GetCpu().GetNumberCores(),
+		laptop.GetCpu().GetMinGhz(),
+		ramBits(laptop.GetRam()),
+		timestampToNanos(laptop.GetUpdatedAt()),
+		data,
+		laptop.GetId(),
+		timestampToNanos(lastUpdatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot update laptop: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("cannot update laptop: %w", err)
+	}
+	if affected > 0 {
+		store.publish(LaptopEvent{Type: LaptopUpdated, ID: laptop.GetId(), Laptop: proto.Clone(laptop).(*pb.Laptop)})
+		return nil
+	}
+
+	// nothing was updated, find out whether the laptop is gone or was changed by someone else
+	current, err := store.Find(laptop.GetId())
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrNotFound
+	}
+	return ErrConflict
+}
+
+func (store *SQLiteLaptopStore) Delete(id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	result, err := store.db.Exec(`DELETE FROM laptops WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("cannot delete laptop: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("cannot delete laptop: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	store.publish(LaptopEvent{Type: LaptopDeleted, ID: id})
+	return nil
+}
+
+func (store *SQLiteLaptopStore) Find(id string) (*pb.Laptop, error) {
+	var data []byte
+	err := store.db.QueryRow(`SELECT data FROM laptops WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query laptop: %w", err)
+	}
+
+	laptop := &pb.Laptop{}
+	err = proto.Unmarshal(data, laptop)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal laptop: %w", err)
+	}
+	return laptop, nil
+}
+
+func (store *SQLiteLaptopStore) Search(ctx context.Context, filter *pb.Filter, afterID string, found func(laptop *pb.Laptop) error) error {
+	rows, err := store.db.QueryContext(
+		ctx,
+		`SELECT data FROM laptops
+		WHERE id > ? AND price_usd <= ? AND cpu_cores >= ? AND cpu_min_ghz >= ? AND ram_bits >= ?
+		ORDER BY id`,
+		afterID,
+		maxPriceUsd(filter),
+		filter.GetMinCpuCore(),
+		filter.GetMinCpuGhz(),
+		ramBits(filter.GetMinRam()),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot search laptops: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		err := rows.Scan(&data)
+		if err != nil {
+			return fmt.Errorf("cannot scan laptop: %w", err)
+		}
+
+		laptop := &pb.Laptop{}
+		err = proto.Unmarshal(data, laptop)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal laptop: %w", err)
+		}
+
+		// the query only narrows down on the indexed columns, isQualified has the final say
+		if !isQualified(filter, laptop) {
+			continue
+		}
+
+		err = found(laptop)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func timestampToNanos(timestamp *timestamppb.Timestamp) int64 {
+	if timestamp == nil {
+		return 0
+	}
+	return timestamp.AsTime().UnixNano()
+}
+
+// ramBits returns the indexed ram size. Sizes that don't fit in the column are stored as its maximum,
+// and unknown units count as zero like in the memory store.
+func ramBits(memory *pb.Memory) int64 {
+	bits, err := memsize.ToBits(memory)
+	if errors.Is(err, memsize.ErrOverflow) || bits > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	if err != nil {
+		return 0
+	}
+	return int64(bits)
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey ||
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type SQLiteRatingStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteRatingStore(db *sql.DB) *SQLiteRatingStore {
+	return &SQLiteRatingStore{db: db}
+}
+
+func (store *SQLiteRatingStore) Add(laptopID string, score float64) (*Rating, error) {
+	rating := &Rating{}
+	err := store.db.QueryRow(
+		`INSERT INTO ratings (laptop_id, count, sum) VALUES (?, 1, ?)
+		ON CONFLICT (laptop_id) DO UPDATE SET count = count + 1, sum = sum + excluded.sum
+		RETURNING count, sum`,
+		laptopID,
+		score,
+	).Scan(&rating.Count, &rating.Sum)
+	if err != nil {
+		return nil, fmt.Errorf("cannot add rating: %w", err)
+	}
+	return rating, nil
+}
+
+func (store *SQLiteRatingStore) Find(laptopID string) (*Rating, error) {
+	rating := &Rating{}
+	err := store.db.QueryRow(
+		`SELECT count, sum FROM ratings WHERE laptop_id = ?`,
+		laptopID,
+	).Scan(&rating.Count, &rating.Sum)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query rating: %w", err)
+	}
+	return rating, nil
+}
+
+func (store *SQLiteRatingStore) Put(laptopID string, rating *Rating) error {
+	_, err := store.db.Exec(
+		`INSERT INTO ratings (laptop_id, count, sum) VALUES (?, ?, ?)
+		ON CONFLICT (laptop_id) DO UPDATE SET count = excluded.count, sum = excluded.sum`,
+		laptopID,
+		rating.Count,
+		rating.Sum,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot put rating: %w", err)
+	}
+	return nil
+}
+
+func (store *SQLiteRatingStore) Range(ctx context.Context, found func(laptopID string, rating *Rating) error) error {
+	rows, err := store.db.QueryContext(ctx, `SELECT laptop_id, count, sum FROM ratings ORDER BY laptop_id`)
+	if err != nil {
+		return fmt.Errorf("cannot query ratings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var laptopID string
+		rating := &Rating{}
+		err := rows.Scan(&laptopID, &rating.Count, &rating.Sum)
+		if err != nil {
+			return fmt.Errorf("cannot scan rating: %w", err)
+		}
+		err = found(laptopID, rating)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
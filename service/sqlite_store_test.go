@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSQLiteLaptopStore(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "pcbook.db")
+	db, err := OpenSQLiteDB(dbPath)
+	require.NoError(t, err)
+
+	store := NewSQLiteLaptopStore(db)
+
+	laptop := sample.NewLaptop()
+	laptop.PriceUsd = 2000
+	laptop.Cpu.NumberCores = 6
+	laptop.Cpu.MinGhz = 3.0
+	laptop.Ram = &pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE}
+
+	err = store.Save(laptop)
+	require.NoError(t, err)
+	require.Equal(t, ErrAlreadyExists, store.Save(laptop))
+
+	updated := proto.Clone(laptop).(*pb.Laptop)
+	updated.UpdatedAt = timestamppb.Now()
+	err = store.Update(updated, laptop.GetUpdatedAt())
+	require.NoError(t, err)
+	require.Equal(t, ErrConflict, store.Update(updated, laptop.GetUpdatedAt()))
+
+	// reopen the database to make sure the data survives a restart
+	require.NoError(t, db.Close())
+	db, err = OpenSQLiteDB(dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+	store = NewSQLiteLaptopStore(db)
+
+	other, err := store.Find(laptop.Id)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(updated, other))
+
+	filter := &pb.Filter{
+		MaxPriceUsd: 3000,
+		MinCpuCore:  4,
+		MinCpuGhz:   2.5,
+		MinRam:      &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE},
+	}
+	found := 0
+	err = store.Search(context.Background(), filter, "", func(laptop *pb.Laptop) error {
+		found++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, found)
+
+	found = 0
+	err = store.Search(context.Background(), &pb.Filter{}, "", func(laptop *pb.Laptop) error {
+		found++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, found)
+
+	filter.MaxPriceUsd = 1000
+	err = store.Search(context.Background(), filter, "", func(laptop *pb.Laptop) error {
+		t.Fatalf("unexpected laptop %s", laptop.GetId())
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Delete(laptop.Id))
+	require.Equal(t, ErrNotFound, store.Delete(laptop.Id))
+
+	ratingStore := NewSQLiteRatingStore(db)
+	_, err = ratingStore.Add(laptop.Id, 8)
+	require.NoError(t, err)
+	rating, err := ratingStore.Add(laptop.Id, 7)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), rating.Count)
+	require.Equal(t, 15.0, rating.Sum)
+
+	require.NoError(t, ratingStore.Put(laptop.Id, &Rating{Count: 5, Sum: 40}))
+	var ratings []*Rating
+	err = ratingStore.Range(context.Background(), func(laptopID string, rating *Rating) error {
+		require.Equal(t, laptop.Id, laptopID)
+		ratings = append(ratings, rating)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*Rating{{Count: 5, Sum: 40}}, ratings)
+
+	userStore := NewSQLiteUserStore(db)
+	user, err := NewUser("admin1", "secret", "admin")
+	require.NoError(t, err)
+	require.NoError(t, userStore.Save(user))
+	require.Equal(t, ErrAlreadyExists, userStore.Save(user))
+	other2, err := userStore.Find("admin1")
+	require.NoError(t, err)
+	require.True(t, other2.IsCorrectPassword("secret"))
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type SQLiteUserStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteUserStore(db *sql.DB) *SQLiteUserStore {
+	return &SQLiteUserStore{db: db}
+}
+
+func (store *SQLiteUserStore) Save(user *User) error {
+	_, err := store.db.Exec(
+		`INSERT INTO users (username, hashed_password, role) VALUES (?, ?, ?)`,
+		user.Username,
+		user.HashedPassword,
+		user.Role,
+	)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("cannot insert user: %w", err)
+	}
+	return nil
+}
+
+func (store *SQLiteUserStore) Find(username string) (*User, error) {
+	user := &User{}
+	err := store.db.QueryRow(
+		`SELECT username, hashed_password, role FROM users WHERE username = ?`,
+		username,
+	).Scan(&user.Username, &user.HashedPassword, &user.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query user: %w", err)
+	}
+	return user, nil
+}
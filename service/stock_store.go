@@ -0,0 +1,242 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"example.com/pcbook/pb"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var ErrOutOfStock = errors.New("not enough units available")
+var ErrBelowReserved = errors.New("stock cannot be set below the reserved units")
+
+type StockStore interface {
+	// Set replaces the number of units of a laptop in a warehouse
+	Set(laptopID string, warehouse string, onHand uint32) (*pb.Stock, error)
+	// Find returns the stock of a laptop in every warehouse, ordered by warehouse
+	Find(laptopID string) ([]*pb.Stock, error)
+	// Available returns the units of a laptop that can still be reserved, over all warehouses
+	Available(laptopID string) (uint32, error)
+	// Reserve holds units until the reservation is committed, released or expires.
+	// An empty warehouse picks the first one with enough available units.
+	Reserve(laptopID string, warehouse string, quantity uint32, ttl time.Duration) (*pb.Reservation, error)
+	// Commit removes the reserved units from the stock
+	Commit(reservationID string) (*pb.Stock, error)
+	// Release gives the reserved units back
+	Release(reservationID string) (*pb.Stock, error)
+	// ReclaimExpired releases the expired reservations and returns how many there were
+	ReclaimExpired() (int, error)
+}
+
+type stockKey struct {
+	laptopID  string
+	warehouse string
+}
+
+type InMemoryStockStore struct {
+	mutex        sync.Mutex
+	stocks       map[stockKey]*pb.Stock
+	reservations map[string]*pb.Reservation
+	now          func() time.Time
+	done         chan struct{}
+}
+
+// NewInMemoryStockStore returns a store that reclaims expired reservations every reclaimInterval,
+// until it is closed. Expired reservations are also refused by Commit, so the interval only bounds
+// how long their units stay unavailable.
+func NewInMemoryStockStore(reclaimInterval time.Duration) *InMemoryStockStore {
+	store := &InMemoryStockStore{
+		stocks:       make(map[stockKey]*pb.Stock),
+		reservations: make(map[string]*pb.Reservation),
+		now:          time.Now,
+		done:         make(chan struct{}),
+	}
+
+	if reclaimInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(reclaimInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-store.done:
+					return
+				case <-ticker.C:
+					n, err := store.ReclaimExpired()
+					if err != nil {
+						log.Printf("cannot reclaim expired reservations: %v", err)
+					} else if n > 0 {
+						log.Printf("reclaimed %d expired reservations", n)
+					}
+				}
+			}
+		}()
+	}
+	return store
+}
+
+// Close stops reclaiming expired reservations in the background
+func (store *InMemoryStockStore) Close() error {
+	close(store.done)
+	return nil
+}
+
+func (store *InMemoryStockStore) Set(laptopID string, warehouse string, onHand uint32) (*pb.Stock, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	key := stockKey{laptopID, warehouse}
+	stock := store.stocks[key]
+	if stock == nil {
+		stock = &pb.Stock{LaptopId: laptopID, Warehouse: warehouse}
+		store.stocks[key] = stock
+	}
+	if onHand < stock.Reserved {
+		return nil, fmt.Errorf("%w: %d < %d", ErrBelowReserved, onHand, stock.Reserved)
+	}
+	stock.OnHand = onHand
+	return proto.Clone(stock).(*pb.Stock), nil
+}
+
+func (store *InMemoryStockStore) Find(laptopID string) ([]*pb.Stock, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.find(laptopID), nil
+}
+
+// find returns copies of the stocks of a laptop, the mutex must be held
+func (store *InMemoryStockStore) find(laptopID string) []*pb.Stock {
+	var stocks []*pb.Stock
+	for key, stock := range store.stocks {
+		if key.laptopID == laptopID {
+			stocks = append(stocks, proto.Clone(stock).(*pb.Stock))
+		}
+	}
+	sort.Slice(stocks, func(i, j int) bool {
+		return stocks[i].Warehouse < stocks[j].Warehouse
+	})
+	return stocks
+}
+
+func (store *InMemoryStockStore) Available(laptopID string) (uint32, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var available uint32
+	for key, stock := range store.stocks {
+		if key.laptopID == laptopID {
+			available += stock.OnHand - stock.Reserved
+		}
+	}
+	return available, nil
+}
+
+func (store *InMemoryStockStore) Reserve(laptopID string, warehouse string, quantity uint32, ttl time.Duration) (*pb.Reservation, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var stock *pb.Stock
+	if warehouse != "" {
+		stock = store.stocks[stockKey{laptopID, warehouse}]
+		if stock == nil {
+			return nil, fmt.Errorf("%w: laptop %s has no stock in warehouse %s", ErrOutOfStock, laptopID, warehouse)
+		}
+	} else {
+		for _, s := range store.find(laptopID) {
+			if s.OnHand-s.Reserved >= quantity {
+				stock = store.stocks[stockKey{laptopID, s.Warehouse}]
+				break
+			}
+		}
+		if stock == nil {
+			return nil, fmt.Errorf("%w: no warehouse has %d units of laptop %s", ErrOutOfStock, quantity, laptopID)
+		}
+	}
+
+	available := stock.OnHand - stock.Reserved
+	if available < quantity {
+		return nil, fmt.Errorf("%w: %d < %d", ErrOutOfStock, available, quantity)
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate reservation id: %w", err)
+	}
+	reservation := &pb.Reservation{
+		Id:        id.String(),
+		LaptopId:  laptopID,
+		Warehouse: stock.Warehouse,
+		Quantity:  quantity,
+		ExpiresAt: timestamppb.New(store.now().Add(ttl)),
+	}
+	stock.Reserved += quantity
+	store.reservations[reservation.Id] = reservation
+	return proto.Clone(reservation).(*pb.Reservation), nil
+}
+
+func (store *InMemoryStockStore) Commit(reservationID string) (*pb.Stock, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	reservation, err := store.take(reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if !store.now().Before(reservation.ExpiresAt.AsTime()) {
+		return nil, fmt.Errorf("%w: reservation %s has expired", ErrNotFound, reservationID)
+	}
+
+	stock := store.stocks[stockKey{reservation.LaptopId, reservation.Warehouse}]
+	stock.OnHand -= reservation.Quantity
+	return proto.Clone(stock).(*pb.Stock), nil
+}
+
+func (store *InMemoryStockStore) Release(reservationID string) (*pb.Stock, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	reservation, err := store.take(reservationID)
+	if err != nil {
+		return nil, err
+	}
+	stock := store.stocks[stockKey{reservation.LaptopId, reservation.Warehouse}]
+	return proto.Clone(stock).(*pb.Stock), nil
+}
+
+// take removes a reservation and unreserves its units, the mutex must be held
+func (store *InMemoryStockStore) take(reservationID string) (*pb.Reservation, error) {
+	reservation := store.reservations[reservationID]
+	if reservation == nil {
+		return nil, fmt.Errorf("%w: reservation %s", ErrNotFound, reservationID)
+	}
+	delete(store.reservations, reservationID)
+
+	stock := store.stocks[stockKey{reservation.LaptopId, reservation.Warehouse}]
+	stock.Reserved -= reservation.Quantity
+	return reservation, nil
+}
+
+func (store *InMemoryStockStore) ReclaimExpired() (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := store.now()
+	n := 0
+	for id, reservation := range store.reservations {
+		if !now.Before(reservation.ExpiresAt.AsTime()) {
+			_, err := store.take(id)
+			if err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+)
+
+func TestStockStoreReserve(t *testing.T) {
+	t.Parallel()
+
+	store := NewInMemoryStockStore(0)
+	defer store.Close()
+
+	_, err := store.Set("laptop", "berlin", 2)
+	require.NoError(t, err)
+	_, err = store.Set("laptop", "paris", 5)
+	require.NoError(t, err)
+
+	// berlin doesn't have enough units, so paris is picked
+	reservation, err := store.Reserve("laptop", "", 3, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, "paris", reservation.GetWarehouse())
+
+	_, err = store.Reserve("laptop", "berlin", 3, time.Minute)
+	require.True(t, errors.Is(err, ErrOutOfStock))
+
+	_, err = store.Set("laptop", "paris", 2)
+	require.True(t, errors.Is(err, ErrBelowReserved))
+
+	available, err := store.Available("laptop")
+	require.NoError(t, err)
+	require.Equal(t, uint32(4), available)
+
+	stock, err := store.Commit(reservation.GetId())
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), stock.GetOnHand())
+	require.Equal(t, uint32(0), stock.GetReserved())
+
+	_, err = store.Commit(reservation.GetId())
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	reservation, err = store.Reserve("laptop", "berlin", 2, time.Minute)
+	require.NoError(t, err)
+	stock, err = store.Release(reservation.GetId())
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), stock.GetOnHand())
+	require.Equal(t, uint32(0), stock.GetReserved())
+}
+
+func TestStockStoreConcurrentReserve(t *testing.T) {
+	t.Parallel()
+
+	store := NewInMemoryStockStore(0)
+	defer store.Close()
+
+	_, err := store.Set("laptop", "berlin", 10)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	reserved := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Reserve("laptop", "berlin", 1, time.Minute)
+			if err == nil {
+				mutex.Lock()
+				reserved++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 10, reserved)
+	available, err := store.Available("laptop")
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), available)
+}
+
+func TestStockStoreReclaimExpired(t *testing.T) {
+	t.Parallel()
+
+	store := NewInMemoryStockStore(0)
+	defer store.Close()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	_, err := store.Set("laptop", "berlin", 3)
+	require.NoError(t, err)
+	expiring, err := store.Reserve("laptop", "berlin", 2, time.Minute)
+	require.NoError(t, err)
+	_, err = store.Reserve("laptop", "berlin", 1, time.Hour)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	n, err := store.ReclaimExpired()
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	available, err := store.Available("laptop")
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), available)
+
+	_, err = store.Commit(expiring.GetId())
+	require.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"example.com/pcbook/pb"
+)
+
+// TextMatcher is implemented by laptop stores that keep a full-text index
+type TextMatcher interface {
+	// MatchText returns the relevance score of every laptop that matches text, keyed by laptop id
+	MatchText(text string) (map[string]float64, error)
+}
+
+// textIndex is an inverted index over the brand, name, CPU name and GPU names of laptops.
+// It is not safe for concurrent use, the owning store serializes access.
+type textIndex struct {
+	postings map[string]map[string]int
+	docs     map[string][]string
+}
+
+func newTextIndex() *textIndex {
+	return &textIndex{
+		postings: make(map[string]map[string]int),
+		docs:     make(map[string][]string),
+	}
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func laptopTokens(laptop *pb.Laptop) []string {
+	tokens := tokenize(laptop.GetBrand())
+	tokens = append(tokens, tokenize(laptop.GetName())...)
+	tokens = append(tokens, tokenize(laptop.GetCpu().GetName())...)
+	for _, gpu := range laptop.GetGpus() {
+		tokens = append(tokens, tokenize(gpu.GetName())...)
+	}
+	return tokens
+}
+
+func (index *textIndex) add(laptop *pb.Laptop) {
+	index.remove(laptop.GetId())
+
+	tokens := laptopTokens(laptop)
+	for _, token := range tokens {
+		posting := index.postings[token]
+		if posting == nil {
+			posting = make(map[string]int)
+			index.postings[token] = posting
+		}
+		posting[laptop.GetId()]++
+	}
+	index.docs[laptop.GetId()] = tokens
+}
+
+func (index *textIndex) remove(id string) {
+	for _, token := range index.docs[id] {
+		posting := index.postings[token]
+		delete(posting, id)
+		if len(posting) == 0 {
+			delete(index.postings, token)
+		}
+	}
+	delete(index.docs, id)
+}
+
+// match scores laptops with tf-idf, scaled by the fraction of the query tokens they contain,
+// so that laptops matching more of the query rank first
+func (index *textIndex) match(text string) map[string]float64 {
+	queryTokens := unique(tokenize(text))
+	scores := make(map[string]float64)
+	matched := make(map[string]int)
+
+	for _, token := range queryTokens {
+		posting := index.postings[token]
+		if len(posting) == 0 {
+			continue
+		}
+		idf := math.Log(1 + float64(len(index.docs))/float64(len(posting)))
+		for id, frequency := range posting {
+			scores[id] += float64(frequency) * idf
+			matched[id]++
+		}
+	}
+
+	for id := range scores {
+		scores[id] *= float64(matched[id]) / float64(len(queryTokens))
+	}
+	return scores
+}
+
+func unique(tokens []string) []string {
+	seen := make(map[string]bool, len(tokens))
+	result := tokens[:0]
+	for _, token := range tokens {
+		if !seen[token] {
+			seen[token] = true
+			result = append(result, token)
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+)
+
+func TestTextIndex(t *testing.T) {
+	t.Parallel()
+
+	newLaptop := func(brand, name, gpu string) *pb.Laptop {
+		laptop := sample.NewLaptop()
+		laptop.Brand = brand
+		laptop.Name = name
+		laptop.Cpu.Name = "Core i7 13700K"
+		laptop.Gpus = []*pb.GPU{{Name: gpu}}
+		return laptop
+	}
+
+	p1 := newLaptop("Lenovo", "Thinkpad P1", "RTX 3080")
+	x1 := newLaptop("Lenovo", "Thinkpad X1", "RTX 2060")
+	xps := newLaptop("Dell", "XPS", "RX 5700-XT")
+
+	store := NewInMemoryLaptopStore()
+	for _, laptop := range []*pb.Laptop{p1, x1, xps} {
+		require.NoError(t, store.Save(laptop))
+	}
+
+	scores, err := store.MatchText("ThinkPad P1")
+	require.NoError(t, err)
+	require.Len(t, scores, 2)
+	require.True(t, scores[p1.Id] > scores[x1.Id])
+
+	scores, err = store.MatchText("rtx 3080")
+	require.NoError(t, err)
+	require.Len(t, scores, 2)
+	require.True(t, scores[p1.Id] > scores[x1.Id])
+
+	scores, err = store.MatchText("5700")
+	require.NoError(t, err)
+	require.Len(t, scores, 1)
+	require.Contains(t, scores, xps.Id)
+
+	// the index follows updates and deletes
+	renamed := newLaptop("Dell", "Latitude", "RX 590")
+	renamed.Id = xps.Id
+	renamed.UpdatedAt = xps.UpdatedAt
+	require.NoError(t, store.Update(renamed, xps.GetUpdatedAt()))
+	require.NoError(t, store.Delete(p1.Id))
+
+	scores, err = store.MatchText("xps 3080")
+	require.NoError(t, err)
+	require.Empty(t, scores)
+
+	scores, err = store.MatchText("latitude")
+	require.NoError(t, err)
+	require.Contains(t, scores, xps.Id)
+}
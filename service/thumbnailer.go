@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync"
+
+	"example.com/pcbook/thumbnail"
+)
+
+// Thumbnailer makes the thumbnails of uploaded images in the background, on a fixed number of workers.
+// Images wait in a bounded queue, so a burst of uploads uses neither unbounded memory nor unbounded CPU.
+type Thumbnailer struct {
+	imageStore ImageStore
+	sizes      []int
+
+	mutex  sync.Mutex
+	closed bool
+	queue  chan string
+	wg     sync.WaitGroup
+}
+
+func NewThumbnailer(imageStore ImageStore, sizes []int, workers int, queueSize int) *Thumbnailer {
+	thumbnailer := &Thumbnailer{
+		imageStore: imageStore,
+		sizes:      sizes,
+		queue:      make(chan string, queueSize),
+	}
+	thumbnailer.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer thumbnailer.wg.Done()
+			for imageID := range thumbnailer.queue {
+				err := thumbnailer.generate(imageID)
+				if err != nil {
+					log.Printf("cannot make thumbnails of image %s: %v", imageID, err)
+				}
+			}
+		}()
+	}
+	return thumbnailer
+}
+
+// Enqueue returns false if the queue is full or the thumbnailer is closed,
+// the image is then served without thumbnails
+func (thumbnailer *Thumbnailer) Enqueue(imageID string) bool {
+	thumbnailer.mutex.Lock()
+	defer thumbnailer.mutex.Unlock()
+
+	if thumbnailer.closed {
+		return false
+	}
+	select {
+	case thumbnailer.queue <- imageID:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close waits for the images already queued
+func (thumbnailer *Thumbnailer) Close() error {
+	thumbnailer.mutex.Lock()
+	if !thumbnailer.closed {
+		thumbnailer.closed = true
+		close(thumbnailer.queue)
+	}
+	thumbnailer.mutex.Unlock()
+
+	thumbnailer.wg.Wait()
+	return nil
+}
+
+func (thumbnailer *Thumbnailer) generate(imageID string) error {
+	info, err := thumbnailer.imageStore.Find(imageID)
+	if err != nil || info == nil {
+		return err
+	}
+	if thumbnail.TooLarge(info.Width, info.Height) {
+		log.Printf("image %s is %dx%d, which is too large for thumbnails", imageID, info.Width, info.Height)
+		return nil
+	}
+	data, err := os.ReadFile(info.Path)
+	if err != nil {
+		return err
+	}
+
+	thumbnails, err := thumbnail.Generate(data, thumbnailer.sizes)
+	if errors.Is(err, thumbnail.ErrUnsupported) {
+		log.Printf("image %s is %s, which has no thumbnails", imageID, info.MIMEType)
+		return nil
+	}
+	if errors.Is(err, thumbnail.ErrTooLarge) {
+		log.Printf("image %s is too large for thumbnails", imageID)
+		return nil
+	}
+	if err != nil || len(thumbnails) == 0 {
+		return err
+	}
+
+	err = thumbnailer.imageStore.SaveThumbnails(imageID, info.Checksum, thumbnails)
+	if errors.Is(err, ErrNotFound) {
+		// deleted or replaced while the thumbnails were made, a replaced image has been queued again
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("made %d thumbnails of image %s", len(thumbnails), imageID)
+	return nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"example.com/pcbook/thumbnail"
+	"github.com/test-go/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestClientImageThumbnails(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := NewInMemoryLaptopStore()
+	imageFolder := t.TempDir()
+	imageStore, err := NewDiskImageStore(imageFolder)
+	require.NoError(t, err)
+	thumbnailer := NewThumbnailer(imageStore, thumbnail.DefaultSizes, 1, 4)
+
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+
+	laptopServer := NewLaptopServer(laptopStore, imageStore, nil)
+	laptopServer.SetThumbnailer(thumbnailer)
+	grpcServer := grpc.NewServer()
+	pb.RegisterLaptopServiceServer(grpcServer, laptopServer)
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+	laptopClient := newTestLaptopClient(t, listener.Addr().String())
+
+	stream, err := laptopClient.UploadImage(context.Background())
+	require.NoError(t, err)
+	err = stream.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_Info{Info: &pb.ImageInfo{LaptopId: laptop.GetId()}}})
+	require.NoError(t, err)
+	err = stream.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_ChunkData{ChunkData: sample.NewPNG(600, 400)}})
+	require.NoError(t, err)
+	res, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+
+	// closing waits for the queued images
+	require.NoError(t, thumbnailer.Close())
+	require.False(t, thumbnailer.Enqueue(res.GetId()))
+
+	images, err := laptopClient.ListLaptopImages(context.Background(), &pb.ListLaptopImagesRequest{LaptopId: laptop.GetId()})
+	require.NoError(t, err)
+	require.Equal(t, []uint32{128, 512}, images.GetImages()[0].GetThumbnailSizes())
+
+	download := func(thumbnailSize uint32) *pb.ImageInfo {
+		stream, err := laptopClient.DownloadImage(context.Background(), &pb.DownloadImageRequest{
+			ImageId:       res.GetId(),
+			ThumbnailSize: thumbnailSize,
+		})
+		require.NoError(t, err)
+		first, err := stream.Recv()
+		require.NoError(t, err)
+		size := 0
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			size += len(chunk.GetChunkData())
+		}
+		require.EqualValues(t, size, first.GetInfo().GetSize())
+		return first.GetInfo()
+	}
+	require.EqualValues(t, 128, download(100).GetWidth())
+	require.EqualValues(t, 512, download(200).GetWidth())
+	require.EqualValues(t, 600, download(1000).GetWidth())
+	require.EqualValues(t, 600, download(0).GetWidth())
+	require.True(t, download(100).GetSize() < download(0).GetSize())
+
+	// the thumbnails are indexed again on startup, and deleted with their image
+	reopened, err := NewDiskImageStore(imageFolder)
+	require.NoError(t, err)
+	info, err := reopened.Find(res.GetId())
+	require.NoError(t, err)
+	require.Len(t, info.Thumbnails, 2)
+	report, err := reopened.Check()
+	require.NoError(t, err)
+	require.True(t, report.Consistent())
+
+	require.NoError(t, reopened.Delete(res.GetId()))
+	entries, err := os.ReadDir(imageFolder)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestThumbnailerSkipsReplacedImages(t *testing.T) {
+	t.Parallel()
+
+	imageStore, err := NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	imageID, err := imageStore.Save(sample.NewLaptop().GetId(), *bytes.NewBuffer(sample.NewPNG(300, 300)))
+	require.NoError(t, err)
+	info, err := imageStore.Find(imageID)
+	require.NoError(t, err)
+
+	thumbnails, err := thumbnail.Generate(sample.NewPNG(300, 300), thumbnail.DefaultSizes)
+	require.NoError(t, err)
+	require.NoError(t, imageStore.Restore(imageID, info.LaptopID, *bytes.NewBuffer(sample.NewPNG(200, 200))))
+	require.Equal(t, ErrNotFound, imageStore.SaveThumbnails(imageID, info.Checksum, thumbnails))
+
+	// no worker takes the images, so the queue fills up
+	thumbnailer := NewThumbnailer(imageStore, thumbnail.DefaultSizes, 0, 1)
+	require.True(t, thumbnailer.Enqueue(imageID))
+	require.False(t, thumbnailer.Enqueue(imageID))
+}
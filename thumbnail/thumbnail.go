@@ -0,0 +1,141 @@
+// Package thumbnail makes smaller copies of JPEG and PNG images with the standard library codecs.
+// Images are scaled down with a box filter, which averages every source pixel into the thumbnail.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// DefaultSizes are the long edges, in pixels, of the thumbnails made for each image
+var DefaultSizes = []int{128, 512, 1024}
+
+// MaxPixels is the largest image that is resized. Decoding needs two full copies of the pixels,
+// so a small file that declares huge dimensions would otherwise take gigabytes of memory.
+const MaxPixels = 5000 * 5000
+
+var (
+	ErrUnsupported = errors.New("image format cannot be resized")
+	ErrTooLarge    = errors.New("image has too many pixels to be resized")
+)
+
+const jpegQuality = 85
+
+// TooLarge reports whether an image of width × height pixels is over MaxPixels
+func TooLarge(width int, height int) bool {
+	return int64(width)*int64(height) > MaxPixels
+}
+
+// Generate returns a thumbnail for each of sizes smaller than the long edge of the image, by size.
+// The thumbnails keep the aspect ratio and the format of the image.
+func Generate(data []byte, sizes []int) (map[int][]byte, error) {
+	// the header is checked first, so that the pixels of a too large image are never allocated
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %w", err)
+	}
+	if TooLarge(config.Width, config.Height) {
+		return nil, ErrTooLarge
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	longEdge := bounds.Dx()
+	if bounds.Dy() > longEdge {
+		longEdge = bounds.Dy()
+	}
+
+	var rgba *image.RGBA
+	thumbnails := make(map[int][]byte)
+	for _, size := range sizes {
+		if size <= 0 || size >= longEdge {
+			continue
+		}
+		if rgba == nil {
+			// converting once lets Resize read the pixels directly, instead of through At
+			rgba = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+			draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+		}
+
+		data := bytes.Buffer{}
+		thumbnail := Resize(rgba, size)
+		if format == "jpeg" {
+			err = jpeg.Encode(&data, thumbnail, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			err = png.Encode(&data, thumbnail)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %dpx thumbnail: %w", size, err)
+		}
+		thumbnails[size] = data.Bytes()
+	}
+	return thumbnails, nil
+}
+
+// Resize scales src so that its long edge is longEdge pixels. It only scales down:
+// each pixel of the result is the average of the pixels of src it covers.
+func Resize(src *image.RGBA, longEdge int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	width, height := longEdge, longEdge
+	if srcWidth >= srcHeight {
+		height = max(1, srcHeight*longEdge/srcWidth)
+	} else {
+		width = max(1, srcWidth*longEdge/srcHeight)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	origin := src.PixOffset(src.Bounds().Min.X, src.Bounds().Min.Y)
+	for y := 0; y < height; y++ {
+		y0, y1 := span(y, height, srcHeight)
+		for x := 0; x < width; x++ {
+			x0, x1 := span(x, width, srcWidth)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := origin + sy*src.Stride
+				for sx := x0; sx < x1; sx++ {
+					pixel := src.Pix[row+sx*4 : row+sx*4+4]
+					for c := range sum {
+						sum[c] += int(pixel[c])
+					}
+				}
+			}
+
+			count := (y1 - y0) * (x1 - x0)
+			pixel := dst.Pix[dst.PixOffset(x, y):]
+			for c := range sum {
+				pixel[c] = uint8((sum[c] + count/2) / count)
+			}
+		}
+	}
+	return dst
+}
+
+// span returns the range of source pixels covered by pixel i of n, out of srcN
+func span(i int, n int, srcN int) (int, int) {
+	start := i * srcN / n
+	end := (i + 1) * srcN / n
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
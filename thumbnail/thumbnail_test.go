@@ -0,0 +1,81 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+)
+
+func TestResize(t *testing.T) {
+	t.Parallel()
+
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 2; y++ {
+			if x < 2 {
+				src.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				src.Set(x, y, color.RGBA{B: 200, A: 255})
+			}
+		}
+	}
+
+	dst := Resize(src, 2)
+	require.Equal(t, image.Rect(0, 0, 2, 1), dst.Bounds())
+	require.Equal(t, color.RGBA{R: 255, A: 255}, dst.At(0, 0))
+	require.Equal(t, color.RGBA{B: 200, A: 255}, dst.At(1, 0))
+
+	dst = Resize(src, 1)
+	require.Equal(t, image.Rect(0, 0, 1, 1), dst.Bounds())
+	require.Equal(t, color.RGBA{R: 128, B: 100, A: 255}, dst.At(0, 0))
+
+	portrait := image.NewRGBA(image.Rect(0, 0, 300, 1000))
+	require.Equal(t, image.Rect(0, 0, 38, 128), Resize(portrait, 128).Bounds())
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	thumbnails, err := Generate(sample.NewPNG(600, 300), DefaultSizes)
+	require.NoError(t, err)
+	require.Len(t, thumbnails, 2)
+	config, err := png.DecodeConfig(bytes.NewReader(thumbnails[128]))
+	require.NoError(t, err)
+	require.Equal(t, 128, config.Width)
+	require.Equal(t, 64, config.Height)
+	config, err = png.DecodeConfig(bytes.NewReader(thumbnails[512]))
+	require.NoError(t, err)
+	require.Equal(t, 512, config.Width)
+
+	jpegData := bytes.Buffer{}
+	require.NoError(t, jpeg.Encode(&jpegData, image.NewGray(image.Rect(0, 0, 200, 400)), nil))
+	thumbnails, err = Generate(jpegData.Bytes(), DefaultSizes)
+	require.NoError(t, err)
+	require.Len(t, thumbnails, 1)
+	config, err = jpeg.DecodeConfig(bytes.NewReader(thumbnails[128]))
+	require.NoError(t, err)
+	require.Equal(t, 64, config.Width)
+	require.Equal(t, 128, config.Height)
+
+	_, err = Generate([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), DefaultSizes)
+	require.Equal(t, ErrUnsupported, err)
+
+	// a tiny file can declare a huge image in its header
+	huge := sample.NewPNG(1, 1)
+	binary.BigEndian.PutUint32(huge[16:20], 20000)
+	binary.BigEndian.PutUint32(huge[20:24], 20000)
+	binary.BigEndian.PutUint32(huge[29:33], crc32.ChecksumIEEE(huge[12:29]))
+	config, err = png.DecodeConfig(bytes.NewReader(huge))
+	require.NoError(t, err)
+	require.Equal(t, 20000, config.Width)
+	_, err = Generate(huge, DefaultSizes)
+	require.Equal(t, ErrTooLarge, err)
+}
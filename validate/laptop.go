@@ -0,0 +1,133 @@
+// Package validate checks that messages make sense before they are stored.
+// It is shared by the server and the client, so that a client can reject a laptop before sending it.
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/pcbook/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Laptop returns an InvalidArgument status error with an errdetails.BadRequest
+// that has one field violation per problem, or nil if the laptop is valid
+func Laptop(laptop *pb.Laptop) error {
+	violations := LaptopViolations(laptop)
+	if len(violations) == 0 {
+		return nil
+	}
+	return BadRequest("invalid laptop", violations)
+}
+
+// BadRequest returns an InvalidArgument status error carrying the violations
+func BadRequest(msg string, violations []*errdetails.BadRequest_FieldViolation) error {
+	descriptions := make([]string, len(violations))
+	for i, violation := range violations {
+		descriptions[i] = violation.GetField() + ": " + violation.GetDescription()
+	}
+
+	st := status.Newf(codes.InvalidArgument, "%s: %s", msg, strings.Join(descriptions, "; "))
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// FieldViolations returns the field violations attached to a status error, if any
+func FieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = append(violations, badRequest.GetFieldViolations()...)
+		}
+	}
+	return violations
+}
+
+// LaptopViolations lists the problems of a laptop. Missing messages are not reported,
+// but the ones that are present must be consistent.
+func LaptopViolations(laptop *pb.Laptop) []*errdetails.BadRequest_FieldViolation {
+	v := &violations{}
+	if laptop == nil {
+		v.add("laptop", "must be provided")
+		return v.list
+	}
+
+	if cpu := laptop.GetCpu(); cpu != nil {
+		if cpu.GetNumberCores() == 0 {
+			v.add("cpu.number_cores", "must be positive")
+		}
+		if cpu.GetNumberThreads() < cpu.GetNumberCores() {
+			v.add("cpu.number_threads", "must not be less than number_cores (%d)", cpu.GetNumberCores())
+		}
+		v.frequencies("cpu", cpu.GetMinGhz(), cpu.GetMaxGhz())
+	}
+
+	v.memory("ram", laptop.GetRam())
+
+	for i, gpu := range laptop.GetGpus() {
+		field := fmt.Sprintf("gpus[%d]", i)
+		v.frequencies(field, gpu.GetMinGhz(), gpu.GetMaxGhz())
+		v.memory(field+".memory", gpu.GetMemory())
+	}
+
+	for i, storage := range laptop.GetStorages() {
+		v.memory(fmt.Sprintf("storages[%d].memory", i), storage.GetMemory())
+	}
+
+	if screen := laptop.GetScreen(); screen != nil {
+		if screen.GetSizeInch() <= 0 {
+			v.add("screen.size_inch", "must be positive")
+		}
+		resolution := screen.GetResolution()
+		if resolution.GetWidth() == 0 || resolution.GetHeight() == 0 {
+			v.add("screen.resolution", "width and height must be positive")
+		}
+	}
+
+	switch weight := laptop.GetWeight().(type) {
+	case *pb.Laptop_WeightKg:
+		if weight.WeightKg < 0 {
+			v.add("weight_kg", "must not be negative")
+		}
+	case *pb.Laptop_WeightLb:
+		if weight.WeightLb < 0 {
+			v.add("weight_lb", "must not be negative")
+		}
+	}
+
+	if laptop.GetPriceUsd() < 0 {
+		v.add("price_usd", "must not be negative")
+	}
+	return v.list
+}
+
+type violations struct {
+	list []*errdetails.BadRequest_FieldViolation
+}
+
+func (v *violations) add(field string, format string, args ...interface{}) {
+	v.list = append(v.list, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *violations) frequencies(field string, minGhz float64, maxGhz float64) {
+	if minGhz < 0 {
+		v.add(field+".min_ghz", "must not be negative")
+	}
+	if minGhz > maxGhz {
+		v.add(field+".min_ghz", "must not be greater than max_ghz (%g)", maxGhz)
+	}
+}
+
+func (v *violations) memory(field string, memory *pb.Memory) {
+	if memory != nil && memory.GetUnit() == pb.Memory_UNKNOWN {
+		v.add(field+".unit", "must be specified")
+	}
+}
@@ -0,0 +1,100 @@
+package validate
+
+import (
+	"testing"
+
+	"example.com/pcbook/pb"
+	"example.com/pcbook/sample"
+	"github.com/test-go/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLaptop(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		modify func(laptop *pb.Laptop)
+		fields []string
+	}{
+		{
+			name:   "valid",
+			modify: func(laptop *pb.Laptop) {},
+		},
+		{
+			name:   "nil",
+			fields: []string{"laptop"},
+		},
+		{
+			name: "cpu",
+			modify: func(laptop *pb.Laptop) {
+				laptop.Cpu.MinGhz = 4
+				laptop.Cpu.MaxGhz = 3
+				laptop.Cpu.NumberCores = 8
+				laptop.Cpu.NumberThreads = 4
+			},
+			fields: []string{"cpu.number_threads", "cpu.min_ghz"},
+		},
+		{
+			name: "memory_units",
+			modify: func(laptop *pb.Laptop) {
+				laptop.Ram.Unit = pb.Memory_UNKNOWN
+				laptop.Gpus[0].Memory.Unit = pb.Memory_UNKNOWN
+				laptop.Storages[1].Memory.Unit = pb.Memory_UNKNOWN
+			},
+			fields: []string{"ram.unit", "gpus[0].memory.unit", "storages[1].memory.unit"},
+		},
+		{
+			name: "screen",
+			modify: func(laptop *pb.Laptop) {
+				laptop.Screen.Resolution.Width = 0
+			},
+			fields: []string{"screen.resolution"},
+		},
+		{
+			name: "negative",
+			modify: func(laptop *pb.Laptop) {
+				laptop.PriceUsd = -1
+				laptop.Weight = &pb.Laptop_WeightLb{WeightLb: -2}
+			},
+			fields: []string{"weight_lb", "price_usd"},
+		},
+		{
+			name: "missing_messages",
+			modify: func(laptop *pb.Laptop) {
+				laptop.Cpu = nil
+				laptop.Ram = nil
+				laptop.Screen = nil
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var laptop *pb.Laptop
+			if tc.modify != nil {
+				laptop = sample.NewLaptop()
+				tc.modify(laptop)
+			}
+
+			err := Laptop(laptop)
+			if len(tc.fields) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+			violations := FieldViolations(err)
+			fields := make([]string, len(violations))
+			for i, violation := range violations {
+				fields[i] = violation.GetField()
+				require.NotEmpty(t, violation.GetDescription())
+			}
+			require.Equal(t, tc.fields, fields)
+		})
+	}
+}